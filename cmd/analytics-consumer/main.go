@@ -63,7 +63,7 @@ func main() {
 		logg.Fatal("failed to ping postgres", logger.Error(err))
 	}
 	analyticsRepo := postgres.NewAnalyticsRepository(pgpool)
-	analyticsService := analytics.NewService(analyticsRepo, logg)
+	analyticsService := analytics.NewService(analyticsRepo, nil, logg)
 
 	groupID := getEnv("KAFKA_CLICKS_CONSUMER_GROUP", "clicks-analytics-consumer")
 
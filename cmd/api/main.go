@@ -3,29 +3,42 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/analytics"
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
+	"github.com/PavelKhromykhGo/url-shortener/internal/cache"
 	"github.com/PavelKhromykhGo/url-shortener/internal/config"
 	"github.com/PavelKhromykhGo/url-shortener/internal/httpapi"
 	"github.com/PavelKhromykhGo/url-shortener/internal/httpserver"
 	"github.com/PavelKhromykhGo/url-shortener/internal/id"
+	"github.com/PavelKhromykhGo/url-shortener/internal/idempotency"
 	"github.com/PavelKhromykhGo/url-shortener/internal/kafka"
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
+	"github.com/PavelKhromykhGo/url-shortener/internal/ratelimit"
+	"github.com/PavelKhromykhGo/url-shortener/internal/scan"
 	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
 	"github.com/PavelKhromykhGo/url-shortener/internal/storage/postgres"
 	redisstore "github.com/PavelKhromykhGo/url-shortener/internal/storage/redis"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
 	"github.com/PavelKhromykhGo/url-shortener/metrics"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
 	ctx := context.Background()
 
+	runCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -40,6 +53,7 @@ func main() {
 		logger.String("addr", cfg.HTTPAddr))
 
 	metrics.MustInit("api")
+	apiMetrics := metrics.NewAPIMetrics(prometheus.DefaultRegisterer)
 
 	pgPool, err := pgxpool.New(ctx, cfg.PostgresDSN)
 	if err != nil {
@@ -54,20 +68,68 @@ func main() {
 
 	linksRepo := postgres.NewLinksRepository(pgPool)
 	analyticsRepo := postgres.NewAnalyticsRepository(pgPool)
+	idempotencyRepo := postgres.NewIdempotencyRepository(pgPool)
+	if cfg.IdempotencyPurgeInterval > 0 {
+		go idempotency.RunPurger(runCtx, idempotencyRepo, cfg.IdempotencyPurgeInterval, logg)
+	}
+	apiKeysRepo := postgres.NewAPIKeysRepository(pgPool)
+	workspacesRepo := postgres.NewWorkspacesRepository(pgPool)
+	quotaRepo := postgres.NewQuotaRepository(pgPool)
 
-	rdb := redisstore.NewClient(cfg.RedisAddr, cfg.RedisDB, cfg.RedisPassword)
+	rdb, err := redisstore.NewClient(redisstore.Config{
+		Addr:             cfg.RedisAddr,
+		DB:               cfg.RedisDB,
+		Password:         cfg.RedisPassword,
+		MasterName:       cfg.RedisMasterName,
+		SentinelAddrs:    cfg.RedisSentinelAddrs,
+		SentinelPassword: cfg.RedisSentinelPassword,
+		ClusterAddrs:     cfg.RedisClusterAddrs,
+		TLSEnabled:       cfg.RedisTLSEnabled,
+		TLSCAFile:        cfg.RedisTLSCAFile,
+		TLSServerName:    cfg.RedisTLSServerName,
+	})
+	if err != nil {
+		logg.Fatal("failed to create redis client", logger.Error(err))
+	}
 	defer func() {
 		if err := rdb.Close(); err != nil {
 			logg.Warn("failed to close redis client", logger.Error(err))
 		}
 	}()
 
-	var linkCache shortener.LinkCache
+	// Redis may be down at startup or later; the breaker skips it while it fails and
+	// re-enables caching once a probe succeeds.
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		logg.Warn("failed to ping redis", logger.Error(err))
-		linkCache = nil
-	} else {
-		linkCache = redisstore.NewLinkCache(rdb)
+		logg.Warn("failed to ping redis, caching will resume when it is reachable", logger.Error(err))
+	}
+	var linkCache shortener.LinkCache = cache.NewBreaker(
+		redisstore.NewLinkCache(rdb, cfg.StaleCacheTTL),
+		cfg.CacheBreakerFailures,
+		cfg.CacheBreakerOpenTimeout,
+		logg,
+	)
+	if cfg.LocalCacheSize > 0 && cfg.LocalCacheTTL > 0 {
+		bus := redisstore.NewInvalidationBus(rdb, cfg.CacheInvalidationChannel, logg)
+		tiered := cache.NewTiered(linkCache, bus, cfg.LocalCacheSize, cfg.LocalCacheTTL)
+		go bus.Run(runCtx, tiered)
+		linkCache = tiered
+	}
+
+	rateLimits, err := parseRateLimits(cfg)
+	if err != nil {
+		logg.Fatal("failed to parse rate limits", logger.Error(err))
+	}
+	rateLimiter := ratelimit.NewFallback(redisstore.NewRateLimiter(rdb), ratelimit.NewMemory(), logg)
+
+	scanGuard, err := scan.NewGuard(redisstore.NewScanStore(rdb), scan.Config{
+		Threshold:   cfg.ScanThreshold,
+		Window:      cfg.ScanWindow,
+		BlockFor:    cfg.ScanBlockFor,
+		Mode:        scan.Mode(cfg.ScanMode),
+		TarpitDelay: cfg.ScanTarpitDelay,
+	})
+	if err != nil {
+		logg.Fatal("failed to configure scan detection", logger.Error(err))
 	}
 
 	clickProducer, err := kafka.NewClickProducer(cfg.KafkaBrokers, cfg.KafkaClicksTopic, logg)
@@ -80,22 +142,87 @@ func main() {
 		}
 	}()
 
-	idGen := id.NewRandomGenerator(8)
+	authenticators := []auth.Authenticator{
+		auth.NewAPIKeyAuthenticator(apiKeysRepo),
+	}
+	if cfg.JWTJWKSSource != "" {
+		jwks := auth.NewJWKS(cfg.JWTJWKSSource, cfg.JWTJWKSCacheTTL, nil)
+		if err := jwks.Refresh(ctx); err != nil {
+			logg.Warn("failed to load jwks, will retry on demand", logger.Error(err))
+		}
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(auth.JWTConfig{
+			Issuer:     cfg.JWTIssuer,
+			Audience:   cfg.JWTAudience,
+			OwnerClaim: cfg.JWTOwnerClaim,
+		}, jwks))
+		logg.Info("jwt authentication enabled", logger.String("issuer", cfg.JWTIssuer))
+	}
+
+	idGen, err := newIDGenerator(cfg, postgres.NewCodeSequenceRepository(pgPool))
+	if err != nil {
+		logg.Fatal("failed to create short code generator", logger.Error(err))
+	}
+	var codePool *id.Pool
+	if cfg.CodePoolSize > 0 {
+		codePool, err = id.NewPool(idGen, postgres.NewCodePoolRepository(pgPool), id.PoolConfig{
+			Domain:          cfg.BaseURL,
+			Size:            cfg.CodePoolSize,
+			RefillThreshold: cfg.CodePoolRefillThreshold,
+			ReservationTTL:  cfg.CodePoolReservationTTL,
+		}, logg)
+		if err != nil {
+			logg.Fatal("failed to create short code pool", logger.Error(err))
+		}
+		go codePool.Run(runCtx)
+		idGen = codePool
+	}
+
+	workspaceService := workspace.NewService(workspacesRepo, logg)
+
+	quotaPlans, err := quota.ParsePlans(cfg.QuotaPlans)
+	if err != nil {
+		logg.Fatal("failed to parse quota plans", logger.Error(err))
+	}
+	quotaService, err := quota.NewService(quotaRepo, quotaPlans, cfg.QuotaDefaultPlan, logg)
+	if err != nil {
+		logg.Fatal("failed to create quota service", logger.Error(err))
+	}
 
-	analyticsService := analytics.NewService(analyticsRepo, logg)
 	shortenerService := shortener.NewService(shortener.Config{
 		BaseURL:   cfg.BaseURL,
 		LinksRepo: linksRepo,
 		LinkCache: linkCache,
 		IDGen:     idGen,
 		Logger:    logg,
+
+		MaxCodeAttempts: cfg.ShortCodeMaxAttempts,
+		AllowedSchemes:  cfg.AllowedURLSchemes,
+		MaxURLLength:    cfg.MaxURLLength,
+		OwnDomains:      cfg.OwnDomains,
+		NotFoundTTL:     cfg.NotFoundCacheTTL,
+		Workspaces:      workspaceService,
+		Quotas:          quotaService,
 	})
+	analyticsService := analytics.NewService(analyticsRepo, shortenerService, logg)
+
+	// The API reports ready only after the cache warm-up below has finished.
+	ready := &atomic.Bool{}
 
 	deps := httpapi.Deps{
 		Logger:           logg,
 		ShortenerService: shortenerService,
 		ClicksProducer:   clickProducer,
 		AnalyticsService: analyticsService,
+		WorkspaceService: workspaceService,
+		QuotaService:     quotaService,
+		APIMetrics:       apiMetrics,
+		IdempotencyStore: idempotencyRepo,
+		IdempotencyTTL:   cfg.IdempotencyTTL,
+		Authenticators:   authenticators,
+		RateLimiter:      rateLimiter,
+		RateLimits:       rateLimits,
+		ScanGuard:        scanGuard,
+		Ready:            ready,
 	}
 
 	router := httpapi.NewRouter(deps)
@@ -117,6 +244,12 @@ func main() {
 
 	logg.Info("api server started")
 
+	go func() {
+		warmCache(runCtx, shortenerService, cfg, logg)
+		ready.Store(true)
+		logg.Info("api server ready")
+	}()
+
 	sig := <-sigCh
 	logg.Info("shutdown signal received", logger.String("signal", sig.String()))
 
@@ -129,5 +262,46 @@ func main() {
 		logg.Info("http server stopped gracefully")
 	}
 
+	// Unused pooled codes go back to Postgres once no more links can be created.
+	if codePool != nil {
+		stopBackground()
+		if err := codePool.Close(shutdownCtx); err != nil {
+			logg.Warn("failed to release pooled short codes", logger.Error(err))
+		}
+	}
+
 	logg.Info("api server stopped")
 }
+
+// parseRateLimits converts the configured per-group limits.
+func parseRateLimits(cfg *config.Config) (httpapi.RateLimits, error) {
+	var (
+		limits httpapi.RateLimits
+		err    error
+	)
+	if limits.API, err = ratelimit.ParseLimit(cfg.RateLimitAPI); err != nil {
+		return limits, fmt.Errorf("RATE_LIMIT_API: %w", err)
+	}
+	if limits.APIPerIP, err = ratelimit.ParseLimit(cfg.RateLimitAPIPerIP); err != nil {
+		return limits, fmt.Errorf("RATE_LIMIT_API_IP: %w", err)
+	}
+	if limits.Shorten, err = ratelimit.ParseLimit(cfg.RateLimitShorten); err != nil {
+		return limits, fmt.Errorf("RATE_LIMIT_SHORTEN: %w", err)
+	}
+	if limits.Redirect, err = ratelimit.ParseLimit(cfg.RateLimitRedirect); err != nil {
+		return limits, fmt.Errorf("RATE_LIMIT_REDIRECT: %w", err)
+	}
+	return limits, nil
+}
+
+// newIDGenerator builds the short code generator selected by ID_GENERATOR.
+func newIDGenerator(cfg *config.Config, seq id.Sequence) (shortener.IDGenerator, error) {
+	switch cfg.IDGenerator {
+	case "random":
+		return id.NewRandomGenerator(cfg.ShortCodeLength), nil
+	case "sequence":
+		return id.NewSequenceGenerator(seq, []byte(cfg.IDSequenceKey), cfg.ShortCodeLength, cfg.IDSequenceBlock)
+	default:
+		return nil, fmt.Errorf("unknown ID_GENERATOR %q", cfg.IDGenerator)
+	}
+}
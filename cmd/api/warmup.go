@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/config"
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+)
+
+// warmCache preloads the most clicked links into the cache so that the first wave of traffic
+// after a deploy or a Redis flush does not fall through to Postgres. Failures are only logged:
+// a cold cache is slower but still correct.
+func warmCache(ctx context.Context, service shortener.Service, cfg *config.Config, logg logger.Logger) {
+	if cfg.WarmupLinks <= 0 {
+		return
+	}
+
+	if cfg.WarmupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.WarmupTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	since := start.UTC().Add(-cfg.WarmupLookback).Truncate(24 * time.Hour)
+
+	cached, err := service.WarmCache(ctx, since, cfg.WarmupLinks)
+	if err != nil {
+		logg.Warn("failed to warm link cache", logger.Error(err))
+		return
+	}
+
+	logg.Info("link cache warmed",
+		logger.Int("links", cached),
+		logger.String("duration", time.Since(start).String()),
+	)
+}
@@ -0,0 +1,110 @@
+// Command apikey issues and revokes API keys used to authenticate against the URL shortener API.
+//
+// Usage:
+//
+//	apikey issue -owner <owner_id> [-name <name>]
+//	apikey revoke -id <key_id>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
+	"github.com/PavelKhromykhGo/url-shortener/internal/config"
+	"github.com/PavelKhromykhGo/url-shortener/internal/storage/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pgPool, err := pgxpool.New(ctx, cfg.PostgresDSN)
+	if err != nil {
+		log.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer pgPool.Close()
+
+	repo := postgres.NewAPIKeysRepository(pgPool)
+
+	switch os.Args[1] {
+	case "issue":
+		err = issue(ctx, repo, os.Args[2:])
+	case "revoke":
+		err = revoke(ctx, repo, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+// issue creates a new API key for an owner and prints the plaintext secret once.
+func issue(ctx context.Context, repo auth.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("issue", flag.ExitOnError)
+	ownerID := fs.Int64("owner", 0, "owner ID the key authenticates as")
+	name := fs.String("name", "", "human-readable key name")
+	_ = fs.Parse(args)
+
+	if *ownerID <= 0 {
+		return errors.New("-owner is required")
+	}
+
+	plain, key, err := auth.GenerateAPIKey(*ownerID, *name)
+	if err != nil {
+		return err
+	}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		return fmt.Errorf("create api key: %w", err)
+	}
+
+	fmt.Printf("id:     %d\n", key.ID)
+	fmt.Printf("owner:  %d\n", key.OwnerID)
+	fmt.Printf("key:    %s\n", plain)
+	fmt.Println("store the key now: it cannot be shown again")
+	return nil
+}
+
+// revoke disables an API key by ID.
+func revoke(ctx context.Context, repo auth.APIKeyRepository, args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	id := fs.Int64("id", 0, "API key ID to revoke")
+	_ = fs.Parse(args)
+
+	if *id <= 0 {
+		return errors.New("-id is required")
+	}
+
+	if err := repo.RevokeAPIKey(ctx, *id); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			return fmt.Errorf("api key %d not found or already revoked", *id)
+		}
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	fmt.Printf("api key %d revoked\n", *id)
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  apikey issue -owner <owner_id> [-name <name>]")
+	fmt.Fprintln(os.Stderr, "  apikey revoke -id <key_id>")
+	os.Exit(2)
+}
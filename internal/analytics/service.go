@@ -2,6 +2,7 @@ package analytics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,14 +17,20 @@ type Repository interface {
 	GetDailyStats(ctx context.Context, linkID int64, from, to time.Time) ([]DailyStat, error)
 }
 
+// LinkAccessChecker verifies that a user may read a link and its statistics.
+type LinkAccessChecker interface {
+	CheckLinkAccess(ctx context.Context, userID, linkID int64) error
+}
+
 // Service exposes operations for processing clicks and retrieving aggregated statistics.
 type Service interface {
 	ProcessClick(ctx context.Context, event kafka.ClickEvent) error
-	GetDailyStats(ctx context.Context, linkID int64, from, to time.Time) ([]DailyStat, error)
+	GetDailyStats(ctx context.Context, userID, linkID int64, from, to time.Time) ([]DailyStat, error)
 }
 
 type service struct {
 	repo   Repository
+	access LinkAccessChecker
 	logger logger.Logger
 }
 
@@ -34,9 +41,11 @@ type DailyStat struct {
 }
 
 // NewService constructs a Service that delegates persistence to the provided repository and logs operations.
-func NewService(repo Repository, logger logger.Logger) Service {
+// The access checker authorizes statistics reads; it may be nil for services that only process clicks.
+func NewService(repo Repository, access LinkAccessChecker, logger logger.Logger) Service {
 	return &service{
 		repo:   repo,
+		access: access,
 		logger: logger,
 	}
 }
@@ -61,8 +70,16 @@ func (s *service) ProcessClick(ctx context.Context, event kafka.ClickEvent) erro
 	return nil
 }
 
-// GetDailyStats retrieves aggregated daily click statistics for a link within the specified date range.
-func (s *service) GetDailyStats(ctx context.Context, linkID int64, from, to time.Time) ([]DailyStat, error) {
+// GetDailyStats retrieves aggregated daily click statistics for a link within the specified date range,
+// after checking that userID may access the link.
+func (s *service) GetDailyStats(ctx context.Context, userID, linkID int64, from, to time.Time) ([]DailyStat, error) {
+	if s.access == nil {
+		return nil, errors.New("link access checker is not configured")
+	}
+	if err := s.access.CheckLinkAccess(ctx, userID, linkID); err != nil {
+		return nil, err
+	}
+
 	fromDay := from.UTC().Truncate(24 * time.Hour)
 	toDay := to.UTC().Truncate(24 * time.Hour)
 
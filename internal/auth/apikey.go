@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix marks tokens issued as API keys.
+const APIKeyPrefix = "usk_"
+
+// ErrAPIKeyNotFound is returned by APIKeyRepository when no active key matches.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey is an issued API key. Only the hash of the secret is stored.
+type APIKey struct {
+	ID        int64
+	OwnerID   int64
+	Name      string
+	Prefix    string
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKeyRepository persists API keys.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// GetActiveAPIKeyByHash returns a non-revoked key or ErrAPIKeyNotFound.
+	GetActiveAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+// GenerateAPIKey creates a new random API key and returns the plaintext secret together with
+// the record to persist. The plaintext is shown once and never stored.
+func GenerateAPIKey(ownerID int64, name string) (string, *APIKey, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("crypto rand read failed %w", err)
+	}
+
+	secret := hex.EncodeToString(b)
+	plain := APIKeyPrefix + secret
+
+	return plain, &APIKey{
+		OwnerID: ownerID,
+		Name:    name,
+		Prefix:  secret[:8],
+		Hash:    HashAPIKey(plain),
+	}, nil
+}
+
+// HashAPIKey returns the stored representation of a plaintext API key.
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyAuthenticator authenticates tokens issued by GenerateAPIKey.
+type APIKeyAuthenticator struct {
+	repo APIKeyRepository
+}
+
+// NewAPIKeyAuthenticator creates an Authenticator backed by the given repository.
+func NewAPIKeyAuthenticator(repo APIKeyRepository) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{repo: repo}
+}
+
+var _ Authenticator = (*APIKeyAuthenticator)(nil)
+
+// Authenticate looks up the hashed token and returns its owner.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (int64, error) {
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return 0, ErrUnsupportedCredential
+	}
+
+	key, err := a.repo.GetActiveAPIKeyByHash(ctx, HashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return 0, ErrInvalidCredential
+		}
+		return 0, fmt.Errorf("get api key: %w", err)
+	}
+	return key.OwnerID, nil
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUnsupportedCredential is returned by an Authenticator when the token is not in its format,
+	// so the next authenticator can try it.
+	ErrUnsupportedCredential = errors.New("unsupported credential")
+	// ErrInvalidCredential is returned when a token is in a supported format but is unknown, revoked or malformed.
+	ErrInvalidCredential = errors.New("invalid credential")
+)
+
+// Authenticator resolves a bearer token into the owner it belongs to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (ownerID int64, err error)
+}
+
+type ownerKey struct{}
+
+// WithOwner returns a copy of ctx carrying the authenticated owner ID.
+func WithOwner(ctx context.Context, ownerID int64) context.Context {
+	return context.WithValue(ctx, ownerKey{}, ownerID)
+}
+
+// OwnerFromContext returns the authenticated owner ID stored by WithOwner.
+func OwnerFromContext(ctx context.Context) (int64, bool) {
+	ownerID, ok := ctx.Value(ownerKey{}).(int64)
+	return ownerID, ok
+}
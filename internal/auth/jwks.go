@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minJWKSRefreshInterval is the minimum time between two fetches of the key set;
+// within it the cached keys, or the last fetch error, are reused.
+const minJWKSRefreshInterval = time.Minute
+
+// jwksLoadTimeout bounds a shared fetch, which does not follow any single caller's context.
+const jwksLoadTimeout = 10 * time.Second
+
+// errKeyNotFound is returned when the key set has no key with the requested ID.
+var errKeyNotFound = errors.New("signing key not found")
+
+// jwk is a single JSON Web Key as defined by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS loads public keys from a local file or an HTTP(S) URL and caches them for a TTL.
+type JWKS struct {
+	source string
+	ttl    time.Duration
+	client *http.Client
+
+	// loads collapses concurrent fetches into one.
+	loads singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+// NewJWKS creates a key set that is loaded lazily from source, which is either
+// an http(s) URL or a file path.
+func NewJWKS(source string, ttl time.Duration, client *http.Client) *JWKS {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKS{
+		source: source,
+		ttl:    ttl,
+		client: client,
+	}
+}
+
+// Key returns the public key with the given ID, reloading the set when the cache is stale
+// or the key is unknown.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	fresh := time.Since(j.fetchedAt) < j.ttl
+	j.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	if err := j.refresh(ctx, false); err != nil {
+		if ok {
+			// Serve the cached key while the source is unavailable.
+			return key, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid %q", errKeyNotFound, kid)
+}
+
+// Refresh reloads the key set from its source, bypassing the refresh interval.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	return j.refresh(ctx, true)
+}
+
+// refresh reloads keys. Fetches are at least minJWKSRefreshInterval apart whatever triggers
+// them (expired cache, unknown kid or a failed earlier load), so tokens with random kids or
+// an unavailable source cannot make every request hit the source. Concurrent callers share
+// one in-flight fetch.
+func (j *JWKS) refresh(ctx context.Context, force bool) error {
+	_, err, _ := j.loads.Do("jwks", func() (any, error) {
+		j.mu.Lock()
+		if !force && !j.lastAttempt.IsZero() && time.Since(j.lastAttempt) < minJWKSRefreshInterval {
+			err := j.lastErr
+			j.mu.Unlock()
+			return nil, err
+		}
+		j.lastAttempt = time.Now()
+		j.mu.Unlock()
+
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksLoadTimeout)
+		defer cancel()
+		err := j.fetch(loadCtx)
+
+		j.mu.Lock()
+		j.lastErr = err
+		j.mu.Unlock()
+		return nil, err
+	})
+	return err
+}
+
+// fetch loads and parses the key set and replaces the cached keys.
+func (j *JWKS) fetch(ctx context.Context) error {
+	data, err := j.load(ctx)
+	if err != nil {
+		return fmt.Errorf("load jwks: %w", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+// load reads the raw JWKS document from a URL or a file.
+func (j *JWKS) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseJWKS decodes RSA and EC signing keys from a JWKS document, skipping unsupported ones.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k)
+		case "EC":
+			key, err = ecKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no supported signing keys")
+	}
+	return keys, nil
+}
+
+// rsaKey builds an RSA public key from its JWK modulus and exponent.
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// ecKey builds an ECDSA public key from its JWK curve and coordinates.
+func ecKey(k jwk) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// decodeBigInt decodes a base64url-encoded unsigned big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
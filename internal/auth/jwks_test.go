@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// jwksServer serves body with status and counts the requests it receives.
+func jwksServer(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func ecJWKS(t *testing.T, kid string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	return fmt.Sprintf(`{"keys":[{"kty":"EC","kid":%q,"crv":"P-256","x":%q,"y":%q}]}`,
+		kid, enc(key.X.FillBytes(make([]byte, 32))), enc(key.Y.FillBytes(make([]byte, 32))))
+}
+
+// keyConcurrently calls Key from n goroutines and returns how many calls failed.
+func keyConcurrently(j *JWKS, kid string, n int) int {
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int32
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := j.Key(context.Background(), kid); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(failed.Load())
+}
+
+func TestJWKSThrottlesFailedLoads(t *testing.T) {
+	srv, hits := jwksServer(t, http.StatusInternalServerError, "")
+	j := NewJWKS(srv.URL, 0, nil)
+
+	if failed := keyConcurrently(j, "k1", 20); failed != 20 {
+		t.Fatalf("failed calls = %d, want 20", failed)
+	}
+	if failed := keyConcurrently(j, "k1", 20); failed != 20 {
+		t.Fatalf("failed calls = %d, want 20", failed)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("source fetched %d times, want 1", got)
+	}
+}
+
+func TestJWKSServesCachedKeysWithinInterval(t *testing.T) {
+	srv, hits := jwksServer(t, http.StatusOK, ecJWKS(t, "k1"))
+	// A zero TTL makes every cached key stale.
+	j := NewJWKS(srv.URL, 0, nil)
+
+	if failed := keyConcurrently(j, "k1", 20); failed != 0 {
+		t.Fatalf("failed calls = %d, want 0", failed)
+	}
+	if failed := keyConcurrently(j, "unknown", 5); failed != 5 {
+		t.Fatalf("failed calls for unknown kid = %d, want 5", failed)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("source fetched %d times, want 1", got)
+	}
+
+	if err := j.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("source fetched %d times after explicit refresh, want 2", got)
+	}
+}
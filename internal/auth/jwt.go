@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures JWT bearer authentication.
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// OwnerClaim names the claim holding the numeric owner ID; defaults to "sub".
+	OwnerClaim string
+}
+
+// JWTAuthenticator verifies RS256/ES256 bearer tokens against a JWKS and maps a claim to the owner.
+type JWTAuthenticator struct {
+	cfg  JWTConfig
+	keys *JWKS
+}
+
+// NewJWTAuthenticator creates an Authenticator that verifies tokens with keys from jwks.
+func NewJWTAuthenticator(cfg JWTConfig, jwks *JWKS) *JWTAuthenticator {
+	if cfg.OwnerClaim == "" {
+		cfg.OwnerClaim = "sub"
+	}
+	return &JWTAuthenticator{
+		cfg:  cfg,
+		keys: jwks,
+	}
+}
+
+var _ Authenticator = (*JWTAuthenticator)(nil)
+
+// Authenticate verifies the token signature, issuer, audience and expiry and returns the owner ID.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (int64, error) {
+	if strings.Count(token, ".") != 2 {
+		return 0, ErrUnsupportedCredential
+	}
+
+	var keyErr error
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims,
+		func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+			key, err := a.keys.Key(ctx, kid)
+			if err != nil && !errors.Is(err, errKeyNotFound) {
+				keyErr = err
+			}
+			return key, err
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(a.cfg.Issuer),
+		jwt.WithAudience(a.cfg.Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if keyErr != nil {
+		return 0, fmt.Errorf("get jwt signing key: %w", keyErr)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	ownerID, err := ownerFromClaim(claims[a.cfg.OwnerClaim])
+	if err != nil {
+		return 0, fmt.Errorf("%w: claim %q: %v", ErrInvalidCredential, a.cfg.OwnerClaim, err)
+	}
+	return ownerID, nil
+}
+
+// ownerFromClaim converts a numeric or numeric-string claim value to a positive owner ID.
+func ownerFromClaim(v any) (int64, error) {
+	var (
+		ownerID int64
+		err     error
+	)
+	switch c := v.(type) {
+	case float64:
+		ownerID = int64(c)
+		if float64(ownerID) != c {
+			return 0, errors.New("not an integer")
+		}
+	case string:
+		ownerID, err = strconv.ParseInt(c, 10, 64)
+		if err != nil {
+			return 0, errors.New("not an integer")
+		}
+	case nil:
+		return 0, errors.New("missing")
+	default:
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+
+	if ownerID <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return ownerID, nil
+}
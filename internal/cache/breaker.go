@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+)
+
+const (
+	// maxPendingInvalidations bounds the invalidations kept for replay after failures.
+	maxPendingInvalidations = 10000
+	// replayTimeout bounds one replay of pending invalidations.
+	replayTimeout = 10 * time.Second
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed passes all calls through.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen lets a single probe call through to test whether the cache recovered.
+	BreakerHalfOpen
+	// BreakerOpen skips the cache entirely.
+	BreakerOpen
+)
+
+// String returns the state name used in logs.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Breaker is a shortener.LinkCache that stops calling a failing cache. After failureThreshold
+// consecutive failures it opens and behaves like an empty cache; after openTimeout it lets one
+// probe call through and closes again if the probe succeeds.
+type Breaker struct {
+	next             shortener.LinkCache
+	failureThreshold int
+	openTimeout      time.Duration
+	logger           logger.Logger
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	// pending holds invalidations that failed and must be replayed once the cache responds again.
+	pending   map[linkRef]struct{}
+	replaying bool
+}
+
+// linkRef identifies a link in the cache.
+type linkRef struct {
+	domain string
+	code   string
+}
+
+var _ shortener.LinkCache = (*Breaker)(nil)
+
+// NewBreaker wraps next with a circuit breaker.
+func NewBreaker(next shortener.LinkCache, failureThreshold int, openTimeout time.Duration, logger logger.Logger) *Breaker {
+	metrics.LinkCacheBreakerState.Set(float64(BreakerClosed))
+	return &Breaker{
+		next:             next,
+		failureThreshold: max(failureThreshold, 1),
+		openTimeout:      openTimeout,
+		logger:           logger,
+		now:              time.Now,
+		pending:          make(map[linkRef]struct{}),
+	}
+}
+
+// State returns the current breaker state.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// GetByCode reads through the breaker; an open breaker reports a cache miss.
+func (b *Breaker) GetByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
+	if !b.allow() {
+		return nil, nil
+	}
+	link, err := b.next.GetByCode(ctx, domain, code)
+	b.done(err)
+	return link, err
+}
+
+// GetStaleByCode reads through the breaker; an open breaker reports no stale copy.
+func (b *Breaker) GetStaleByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
+	if !b.allow() {
+		return nil, nil
+	}
+	link, err := b.next.GetStaleByCode(ctx, domain, code)
+	b.done(err)
+	return link, err
+}
+
+// SetByCode writes through the breaker; writes are dropped while it is open.
+func (b *Breaker) SetByCode(ctx context.Context, link *shortener.Link, ttl time.Duration) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.SetByCode(ctx, link, ttl)
+	b.done(err)
+	return err
+}
+
+// SetManyByCode writes through the breaker; writes are dropped while it is open.
+func (b *Breaker) SetManyByCode(ctx context.Context, entries []shortener.CacheEntry) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.SetManyByCode(ctx, entries)
+	b.done(err)
+	return err
+}
+
+// SetNotFound writes through the breaker; writes are dropped while it is open.
+func (b *Breaker) SetNotFound(ctx context.Context, domain, code string, ttl time.Duration) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.SetNotFound(ctx, domain, code, ttl)
+	b.done(err)
+	return err
+}
+
+// Delete always reaches the wrapped cache, even while the breaker is open: a skipped invalidation
+// would let an edited or deleted link be served once the cache recovers. Failed invalidations
+// are queued and replayed as soon as a cache call succeeds again.
+func (b *Breaker) Delete(ctx context.Context, domain, code string) error {
+	err := b.next.Delete(ctx, domain, code)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		b.queueInvalidation(linkRef{domain: domain, code: code})
+	}
+
+	// Only a closed breaker counts failures; an open or probing one keeps its own schedule.
+	if b.State() == BreakerClosed {
+		b.done(err)
+	}
+	return err
+}
+
+// queueInvalidation remembers a failed invalidation for replay.
+func (b *Breaker) queueInvalidation(ref linkRef) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.pending) >= maxPendingInvalidations {
+		b.logger.Error("dropping link cache invalidation, replay queue is full",
+			logger.String("domain", ref.domain),
+			logger.String("code", ref.code),
+		)
+		return
+	}
+	b.pending[ref] = struct{}{}
+}
+
+// replay retries the pending invalidations. Those that fail again stay queued.
+func (b *Breaker) replay() {
+	b.mu.Lock()
+	refs := make([]linkRef, 0, len(b.pending))
+	for ref := range b.pending {
+		refs = append(refs, ref)
+	}
+	clear(b.pending)
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+
+	failed := 0
+	for _, ref := range refs {
+		if err := b.next.Delete(ctx, ref.domain, ref.code); err != nil {
+			b.queueInvalidation(ref)
+			failed++
+		}
+	}
+
+	b.mu.Lock()
+	b.replaying = false
+	b.mu.Unlock()
+
+	b.logger.Info("replayed link cache invalidations",
+		logger.Int("count", len(refs)),
+		logger.Int("failed", failed),
+	)
+}
+
+// allow reports whether a call may reach the wrapped cache.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// done records the outcome of a call that was allowed through.
+func (b *Breaker) done(err error) {
+	// Negative cache hits and callers giving up say nothing about the cache health.
+	failed := err != nil && !errors.Is(err, shortener.ErrNotFound) && !errors.Is(err, context.Canceled)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed && len(b.pending) > 0 && !b.replaying {
+		b.replaying = true
+		go b.replay()
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.logger.Warn("link cache circuit opened",
+				logger.Error(err),
+				logger.Int("failures", b.failures),
+			)
+			b.open()
+		}
+	case BreakerHalfOpen:
+		b.probing = false
+		if failed {
+			b.logger.Warn("link cache probe failed, circuit stays open", logger.Error(err))
+			b.open()
+			return
+		}
+		b.logger.Info("link cache recovered, circuit closed")
+		b.failures = 0
+		b.setState(BreakerClosed)
+	}
+}
+
+// open trips the breaker. Callers must hold b.mu.
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(BreakerOpen)
+}
+
+// setState changes the state and exports it. Callers must hold b.mu.
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	metrics.LinkCacheBreakerState.Set(float64(state))
+}
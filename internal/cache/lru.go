@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+)
+
+// lruEntry is a cached link or, when link is nil, a not-found marker.
+type lruEntry struct {
+	key       string
+	link      *shortener.Link
+	expiresAt time.Time
+}
+
+// lru is a size-bounded least-recently-used map with per-entry expiration. It is safe for concurrent use.
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// newLRU creates an lru holding at most capacity entries.
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns the entry for key if it exists and has not expired.
+func (c *lru) get(key string, now time.Time) (*lruEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !now.Before(e.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+// set stores link under key until expiresAt, evicting the least recently used entry when full.
+func (c *lru) set(key string, link *shortener.Link, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.link = link
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, link: link, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// delete removes key.
+func (c *lru) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// purge removes all entries.
+func (c *lru) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element, c.capacity)
+	c.order.Init()
+}
+
+func (c *lru) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,186 @@
+// Package cache provides shortener.LinkCache decorators.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache tier labels used in metrics.
+const (
+	tierLocal  = "local"
+	tierRemote = "redis"
+)
+
+// Publisher broadcasts link invalidations to other instances.
+type Publisher interface {
+	Publish(ctx context.Context, domain, code string) error
+}
+
+// Tiered is a shortener.LinkCache that keeps hot links in a size-bounded in-process LRU
+// in front of a shared remote cache. Concurrent local misses for the same code share a
+// single remote lookup. Writes go to both tiers.
+//
+// Not-found markers are kept in the remote tier only, so that a link created on another
+// instance is visible immediately; without a remote tier they are kept locally.
+type Tiered struct {
+	local     *lru
+	localTTL  time.Duration
+	remote    shortener.LinkCache
+	publisher Publisher
+	group     singleflight.Group
+	now       func() time.Time
+}
+
+var _ shortener.LinkCache = (*Tiered)(nil)
+
+// NewTiered wraps remote with an in-process tier of at most size entries kept for up to localTTL.
+// A nil remote makes the cache process-local. Deletions are broadcast through publisher, if set,
+// so that other instances evict their local copies.
+func NewTiered(remote shortener.LinkCache, publisher Publisher, size int, localTTL time.Duration) *Tiered {
+	return &Tiered{
+		local:     newLRU(size),
+		localTTL:  localTTL,
+		remote:    remote,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// cacheKey identifies a link in the local tier.
+func cacheKey(domain, code string) string {
+	return domain + "\x00" + code
+}
+
+// GetByCode looks the link up locally, then in the remote tier.
+func (c *Tiered) GetByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
+	key := cacheKey(domain, code)
+
+	if e, ok := c.local.get(key, c.now()); ok {
+		if e.link == nil {
+			observe(tierLocal, "negative_hit")
+			return nil, shortener.ErrNotFound
+		}
+		observe(tierLocal, "hit")
+		return copyLink(e.link), nil
+	}
+	observe(tierLocal, "miss")
+
+	if c.remote == nil {
+		return nil, nil
+	}
+
+	// The shared lookup ignores the caller's cancellation because other callers may be waiting for it.
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		link, err := c.remote.GetByCode(context.WithoutCancel(ctx), domain, code)
+		switch {
+		case errors.Is(err, shortener.ErrNotFound):
+			observe(tierRemote, "negative_hit")
+		case err != nil:
+			observe(tierRemote, "error")
+		case link == nil:
+			observe(tierRemote, "miss")
+		default:
+			observe(tierRemote, "hit")
+			c.setLocal(key, link, c.localTTL)
+		}
+		return link, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link, _ := v.(*shortener.Link)
+	if link == nil {
+		return nil, nil
+	}
+	return copyLink(link), nil
+}
+
+// GetStaleByCode reads the stale copy from the remote tier; the local tier keeps no stale copies.
+func (c *Tiered) GetStaleByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
+	if c.remote == nil {
+		return nil, nil
+	}
+	return c.remote.GetStaleByCode(ctx, domain, code)
+}
+
+// SetByCode stores the link in both tiers.
+func (c *Tiered) SetByCode(ctx context.Context, link *shortener.Link, ttl time.Duration) error {
+	c.setLocal(cacheKey(link.Domain, link.ShortCode), copyLink(link), ttl)
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote.SetByCode(ctx, link, ttl)
+}
+
+// SetManyByCode stores the links in both tiers.
+func (c *Tiered) SetManyByCode(ctx context.Context, entries []shortener.CacheEntry) error {
+	for _, e := range entries {
+		c.setLocal(cacheKey(e.Link.Domain, e.Link.ShortCode), copyLink(e.Link), e.TTL)
+	}
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote.SetManyByCode(ctx, entries)
+}
+
+// SetNotFound marks the code as not found in the remote tier, or locally when there is none.
+func (c *Tiered) SetNotFound(ctx context.Context, domain, code string, ttl time.Duration) error {
+	key := cacheKey(domain, code)
+	if c.remote == nil {
+		c.setLocal(key, nil, ttl)
+		return nil
+	}
+	c.local.delete(key)
+	return c.remote.SetNotFound(ctx, domain, code, ttl)
+}
+
+// Delete removes the code from both tiers and tells other instances to evict it.
+func (c *Tiered) Delete(ctx context.Context, domain, code string) error {
+	c.local.delete(cacheKey(domain, code))
+
+	var err error
+	if c.remote != nil {
+		err = c.remote.Delete(ctx, domain, code)
+	}
+	if c.publisher != nil {
+		err = errors.Join(err, c.publisher.Publish(ctx, domain, code))
+	}
+	return err
+}
+
+// Evict removes the code from the local tier only.
+func (c *Tiered) Evict(domain, code string) {
+	c.local.delete(cacheKey(domain, code))
+}
+
+// Flush empties the local tier.
+func (c *Tiered) Flush() {
+	c.local.purge()
+}
+
+// setLocal stores link locally for the shorter of ttl and the local TTL.
+func (c *Tiered) setLocal(key string, link *shortener.Link, ttl time.Duration) {
+	ttl = min(ttl, c.localTTL)
+	if ttl <= 0 {
+		return
+	}
+	c.local.set(key, link, c.now().Add(ttl))
+}
+
+// copyLink returns a shallow copy so callers cannot mutate cached links.
+func copyLink(link *shortener.Link) *shortener.Link {
+	cp := *link
+	return &cp
+}
+
+// observe counts a cache lookup outcome for a tier.
+func observe(tier, result string) {
+	metrics.LinkCacheLookupsTotal.WithLabelValues(tier, result).Inc()
+}
@@ -5,35 +5,169 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config represents application settings loaded from environment variables.
 type Config struct {
-	Env              string
-	HTTPAddr         string
-	PostgresDSN      string
-	RedisAddr        string
-	RedisDB          int
-	RedisPassword    string
-	KafkaBrokers     []string
-	KafkaClicksTopic string
-	BaseURL          string
+	Env           string
+	HTTPAddr      string
+	PostgresDSN   string
+	RedisAddr     string
+	RedisDB       int
+	RedisPassword string
+	// RedisMasterName enables Sentinel; RedisSentinelAddrs lists the sentinels.
+	RedisMasterName       string
+	RedisSentinelAddrs    []string
+	RedisSentinelPassword string
+	// RedisClusterAddrs enables Redis Cluster with the given seed nodes.
+	RedisClusterAddrs  []string
+	RedisTLSEnabled    bool
+	RedisTLSCAFile     string
+	RedisTLSServerName string
+	KafkaBrokers       []string
+	KafkaClicksTopic   string
+	BaseURL            string
+	// ShortCodeMaxAttempts limits short code regeneration on collisions.
+	ShortCodeMaxAttempts int
+	// IDGenerator selects how short codes are generated: "random" or "sequence".
+	IDGenerator string
+	// ShortCodeLength is the length of generated short codes.
+	ShortCodeLength int
+	// IDSequenceKey is the secret key of the permutation applied to sequence IDs.
+	IDSequenceKey string
+	// IDSequenceBlock is how many sequence IDs an instance reserves at once.
+	IDSequenceBlock int
+	// CodePoolSize is how many reserved short codes an instance keeps buffered; 0 disables the pool.
+	CodePoolSize int
+	// CodePoolRefillThreshold triggers a background refill once fewer codes are buffered.
+	CodePoolRefillThreshold int
+	// CodePoolReservationTTL is how long a pooled code stays reserved in Postgres.
+	CodePoolReservationTTL time.Duration
+	// AllowedURLSchemes lists destination URL schemes accepted on link creation.
+	AllowedURLSchemes []string
+	// MaxURLLength caps the length of destination URLs.
+	MaxURLLength int
+	// OwnDomains lists extra hosts served by this shortener that destinations must not point to.
+	OwnDomains []string
+	// LocalCacheSize bounds the in-process link cache in front of Redis; 0 disables it.
+	LocalCacheSize int
+	// LocalCacheTTL is how long links stay in the in-process cache.
+	LocalCacheTTL time.Duration
+	// CacheBreakerFailures is the number of consecutive Redis cache failures that open the circuit breaker.
+	CacheBreakerFailures int
+	// CacheBreakerOpenTimeout is how long the breaker stays open before probing Redis again.
+	CacheBreakerOpenTimeout time.Duration
+	// CacheInvalidationChannel is the Redis pub/sub channel used to evict links from every instance.
+	CacheInvalidationChannel string
+	// StaleCacheTTL is how long stale link copies are kept for serving redirects during database outages; 0 disables them.
+	StaleCacheTTL time.Duration
+	// WarmupLinks is how many of the most clicked links are cached on startup; 0 disables the warm-up.
+	WarmupLinks int
+	// WarmupLookback is the click history window used to rank links for the warm-up.
+	WarmupLookback time.Duration
+	// WarmupTimeout bounds the warm-up; the API becomes ready when it ends either way.
+	WarmupTimeout time.Duration
+	// NotFoundCacheTTL is how long unknown short codes are cached as not found; 0 disables it.
+	NotFoundCacheTTL time.Duration
+	// IdempotencyTTL is how long Idempotency-Key responses are kept for replay.
+	IdempotencyTTL time.Duration
+	// IdempotencyPurgeInterval is how often expired idempotency keys are deleted; 0 disables the purge.
+	IdempotencyPurgeInterval time.Duration
+	// QuotaPlans defines plans as "name:monthly_links:max_batch_size" entries; empty disables limits.
+	QuotaPlans string
+	// QuotaDefaultPlan is applied to owners without an assigned plan.
+	QuotaDefaultPlan string
+
+	// RateLimitAPI, RateLimitShorten and RateLimitRedirect are "requests/window" limits
+	// per route group; empty or "0" disables the group's limit.
+	RateLimitAPI      string
+	RateLimitShorten  string
+	RateLimitRedirect string
+	// RateLimitAPIPerIP limits /api/v1 requests per client IP before authentication.
+	RateLimitAPIPerIP string
+
+	// ScanThreshold is the number of redirect 404s per ScanWindow that flags a client IP; 0 disables detection.
+	ScanThreshold   int
+	ScanWindow      time.Duration
+	ScanBlockFor    time.Duration
+	ScanMode        string
+	ScanTarpitDelay time.Duration
+
+	// JWTJWKSSource enables JWT authentication when set; it is a JWKS file path or http(s) URL.
+	JWTJWKSSource   string
+	JWTJWKSCacheTTL time.Duration
+	JWTIssuer       string
+	JWTAudience     string
+	JWTOwnerClaim   string
 }
 
 // Load builds Config from environment variables, applying defaults where applicable and validating required fields.
 func Load() (*Config, error) {
 	cfg := &Config{
-		Env:              getEnv("APP_ENV", "dev"),
-		HTTPAddr:         getEnv("HTTP_ADDR", ":8080"),
-		PostgresDSN:      getEnv("POSTGRES_DSN", ""),
-		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisDB:          getEnvInt("REDIS_DB", 0),
-		RedisPassword:    getEnv("REDIS_PASSWORD", ""),
-		KafkaBrokers:     splitComma(getEnv("KAFKA_BROKERS", "localhost:9092")),
-		KafkaClicksTopic: getEnv("KAFKA_CLICKS_TOPIC", "clicks"),
-		BaseURL:          getEnv("BASE_URL", "http://localhost:8080"),
+		Env:           getEnv("APP_ENV", "dev"),
+		HTTPAddr:      getEnv("HTTP_ADDR", ":8080"),
+		PostgresDSN:   getEnv("POSTGRES_DSN", ""),
+		RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisDB:       getEnvInt("REDIS_DB", 0),
+		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+
+		RedisMasterName:       getEnv("REDIS_MASTER_NAME", ""),
+		RedisSentinelAddrs:    splitComma(getEnv("REDIS_SENTINEL_ADDRS", "")),
+		RedisSentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		RedisClusterAddrs:     splitComma(getEnv("REDIS_CLUSTER_ADDRS", "")),
+		RedisTLSEnabled:       getEnvBool("REDIS_TLS_ENABLED", false),
+		RedisTLSCAFile:        getEnv("REDIS_TLS_CA_FILE", ""),
+		RedisTLSServerName:    getEnv("REDIS_TLS_SERVER_NAME", ""),
+		KafkaBrokers:          splitComma(getEnv("KAFKA_BROKERS", "localhost:9092")),
+		KafkaClicksTopic:      getEnv("KAFKA_CLICKS_TOPIC", "clicks"),
+		BaseURL:               getEnv("BASE_URL", "http://localhost:8080"),
+
+		ShortCodeMaxAttempts:     getEnvInt("SHORT_CODE_MAX_ATTEMPTS", 5),
+		IDGenerator:              getEnv("ID_GENERATOR", "random"),
+		ShortCodeLength:          getEnvInt("SHORT_CODE_LENGTH", 8),
+		IDSequenceKey:            getEnv("ID_SEQUENCE_KEY", ""),
+		IDSequenceBlock:          getEnvInt("ID_SEQUENCE_BLOCK", 100),
+		CodePoolSize:             getEnvInt("CODE_POOL_SIZE", 0),
+		CodePoolReservationTTL:   getEnvDuration("CODE_POOL_RESERVATION_TTL", 24*time.Hour),
+		AllowedURLSchemes:        splitComma(getEnv("ALLOWED_URL_SCHEMES", "http,https")),
+		MaxURLLength:             getEnvInt("MAX_URL_LENGTH", 2048),
+		OwnDomains:               splitComma(getEnv("OWN_DOMAINS", "")),
+		LocalCacheSize:           getEnvInt("LOCAL_CACHE_SIZE", 10000),
+		LocalCacheTTL:            getEnvDuration("LOCAL_CACHE_TTL", 5*time.Second),
+		CacheBreakerFailures:     getEnvInt("CACHE_BREAKER_FAILURES", 5),
+		CacheBreakerOpenTimeout:  getEnvDuration("CACHE_BREAKER_OPEN_TIMEOUT", 10*time.Second),
+		CacheInvalidationChannel: getEnv("CACHE_INVALIDATION_CHANNEL", "link:invalidate"),
+		StaleCacheTTL:            getEnvDuration("STALE_CACHE_TTL", 168*time.Hour),
+		WarmupLinks:              getEnvInt("WARMUP_LINKS", 1000),
+		WarmupLookback:           getEnvDuration("WARMUP_LOOKBACK", 72*time.Hour),
+		WarmupTimeout:            getEnvDuration("WARMUP_TIMEOUT", 30*time.Second),
+		NotFoundCacheTTL:         getEnvDuration("NOT_FOUND_CACHE_TTL", 30*time.Second),
+		IdempotencyTTL:           getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyPurgeInterval: getEnvDuration("IDEMPOTENCY_PURGE_INTERVAL", 10*time.Minute),
+		QuotaPlans:               getEnv("QUOTA_PLANS", ""),
+		QuotaDefaultPlan:         getEnv("QUOTA_DEFAULT_PLAN", "free"),
+
+		RateLimitAPI:      getEnv("RATE_LIMIT_API", "600/1m"),
+		RateLimitAPIPerIP: getEnv("RATE_LIMIT_API_IP", "1200/1m"),
+		RateLimitShorten:  getEnv("RATE_LIMIT_SHORTEN", "60/1m"),
+		RateLimitRedirect: getEnv("RATE_LIMIT_REDIRECT", "1200/1m"),
+
+		ScanThreshold:   getEnvInt("SCAN_404_THRESHOLD", 50),
+		ScanWindow:      getEnvDuration("SCAN_WINDOW", time.Minute),
+		ScanBlockFor:    getEnvDuration("SCAN_BLOCK_DURATION", 15*time.Minute),
+		ScanMode:        getEnv("SCAN_MODE", "block"),
+		ScanTarpitDelay: getEnvDuration("SCAN_TARPIT_DELAY", 2*time.Second),
+
+		JWTJWKSSource:   getEnv("JWT_JWKS_SOURCE", ""),
+		JWTJWKSCacheTTL: getEnvDuration("JWT_JWKS_CACHE_TTL", 5*time.Minute),
+		JWTIssuer:       getEnv("JWT_ISSUER", ""),
+		JWTAudience:     getEnv("JWT_AUDIENCE", ""),
+		JWTOwnerClaim:   getEnv("JWT_OWNER_CLAIM", "sub"),
 	}
 
+	cfg.CodePoolRefillThreshold = getEnvInt("CODE_POOL_REFILL_THRESHOLD", cfg.CodePoolSize/4)
+
 	if cfg.PostgresDSN == "" {
 		return nil, fmt.Errorf("POSTGRES_DSN is required")
 	}
@@ -43,6 +177,15 @@ func Load() (*Config, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("BASE_URL is required")
 	}
+	if cfg.RedisMasterName != "" && len(cfg.RedisSentinelAddrs) == 0 {
+		return nil, fmt.Errorf("REDIS_SENTINEL_ADDRS is required when REDIS_MASTER_NAME is set")
+	}
+	if cfg.IDGenerator == "sequence" && cfg.IDSequenceKey == "" {
+		return nil, fmt.Errorf("ID_SEQUENCE_KEY is required when ID_GENERATOR is sequence")
+	}
+	if cfg.JWTJWKSSource != "" && (cfg.JWTIssuer == "" || cfg.JWTAudience == "") {
+		return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required when JWT_JWKS_SOURCE is set")
+	}
 
 	return cfg, nil
 }
@@ -69,6 +212,34 @@ func getEnvInt(key string, def int) int {
 	return v
 }
 
+// getEnvBool retrieves the boolean value of the environment variable named by the key.
+func getEnvBool(key string, def bool) bool {
+	valStr, ok := os.LookupEnv(key)
+	if !ok || valStr == "" {
+		return def
+	}
+
+	v, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvDuration retrieves the time.Duration value of the environment variable named by the key.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	valStr, ok := os.LookupEnv(key)
+	if !ok || valStr == "" {
+		return def
+	}
+
+	v, err := time.ParseDuration(valStr)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
 // splitComma splits a comma-separated string into a slice of trimmed strings.
 func splitComma(s string) []string {
 	if s == "" {
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadStaleCacheTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want time.Duration
+	}{
+		{name: "default", want: 168 * time.Hour},
+		{name: "override", env: "2h", want: 2 * time.Hour},
+		{name: "disabled", env: "0s", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("POSTGRES_DSN", "postgres://localhost/test")
+			t.Setenv("STALE_CACHE_TTL", tt.env)
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.StaleCacheTTL != tt.want {
+				t.Errorf("StaleCacheTTL = %v, want %v", cfg.StaleCacheTTL, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWarmupDefaults(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://localhost/test")
+	t.Setenv("WARMUP_LINKS", "")
+	t.Setenv("WARMUP_LOOKBACK", "")
+	t.Setenv("WARMUP_TIMEOUT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.WarmupLinks != 1000 || cfg.WarmupLookback != 72*time.Hour || cfg.WarmupTimeout != 30*time.Second {
+		t.Errorf("warmup = %d/%v/%v, want 1000/72h0m0s/30s", cfg.WarmupLinks, cfg.WarmupLookback, cfg.WarmupTimeout)
+	}
+}
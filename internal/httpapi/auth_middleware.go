@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+// NewAuthMiddleware authenticates requests with the given authenticators, tried in order,
+// and stores the resolved owner in the request context. Unauthenticated requests get 401.
+func NewAuthMiddleware(log logger.Logger, authenticators ...auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := credentialFromRequest(r)
+			if token == "" {
+				unauthorized(w, "missing credentials")
+				return
+			}
+
+			for _, a := range authenticators {
+				ownerID, err := a.Authenticate(r.Context(), token)
+				if errors.Is(err, auth.ErrUnsupportedCredential) {
+					continue
+				}
+				if errors.Is(err, auth.ErrInvalidCredential) {
+					unauthorized(w, "invalid credentials")
+					return
+				}
+				if err != nil {
+					log.Error("failed to authenticate request", logger.Error(err))
+					http.Error(w, "internal server error", http.StatusInternalServerError)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(auth.WithOwner(r.Context(), ownerID)))
+				return
+			}
+
+			unauthorized(w, "unsupported credentials")
+		})
+	}
+}
+
+// credentialFromRequest extracts a bearer token from the Authorization or X-API-Key header.
+func credentialFromRequest(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// unauthorized answers 401 with a Bearer challenge.
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="url-shortener"`)
+	http.Error(w, msg, http.StatusUnauthorized)
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/go-chi/chi/v5"
+)
+
+// LinkResponse describes a stored short link.
+type LinkResponse struct {
+	ID          string     `json:"id"`
+	WorkspaceID *int64     `json:"workspace_id,omitempty"`
+	ShortCode   string     `json:"short_code"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	IsActive    bool       `json:"is_active"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// UpdateLinkRequest represents a partial update of a link. Omitted fields are left unchanged.
+type UpdateLinkRequest struct {
+	OriginalURL *string `json:"original_url,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// TransferLinkRequest represents payload for moving a link into another workspace.
+type TransferLinkRequest struct {
+	WorkspaceID int64 `json:"workspace_id"`
+}
+
+// ListLinksResponse is a page of links with the cursor for the next page.
+type ListLinksResponse struct {
+	Items      []LinkResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// LinksHandler serves link management requests.
+type LinksHandler struct {
+	service shortener.Service
+	logger  logger.Logger
+}
+
+// NewLinksHandler constructs a handler that delegates link management to the provided service.
+func NewLinksHandler(service shortener.Service, logger logger.Logger) *LinksHandler {
+	return &LinksHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListLinks returns a page of the owner's links filtered by status, destination substring and creation date.
+func (h *LinksHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	const layout = "2006-01-02"
+
+	params := shortener.ListLinksParams{
+		Status:      shortener.LinkStatus(q.Get("status")),
+		URLContains: q.Get("q"),
+		Cursor:      q.Get("cursor"),
+	}
+
+	if v := q.Get("workspace_id"); v != "" {
+		workspaceID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || workspaceID <= 0 {
+			http.Error(w, "invalid `workspace_id` value", http.StatusBadRequest)
+			return
+		}
+		params.WorkspaceID = &workspaceID
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			http.Error(w, "invalid `limit` value", http.StatusBadRequest)
+			return
+		}
+		params.Limit = limit
+	}
+
+	if v := q.Get("created_from"); v != "" {
+		from, err := time.Parse(layout, v)
+		if err != nil {
+			http.Error(w, "invalid `created_from` value, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		params.CreatedFrom = &from
+	}
+
+	if v := q.Get("created_to"); v != "" {
+		to, err := time.Parse(layout, v)
+		if err != nil {
+			http.Error(w, "invalid `created_to` value, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		// created_to is an inclusive day; the service expects an exclusive bound.
+		to = to.AddDate(0, 0, 1)
+		params.CreatedTo = &to
+	}
+
+	page, err := h.service.ListLinks(r.Context(), ownerID, params)
+	if err != nil {
+		if errors.Is(err, shortener.ErrInvalidListParams) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, shortener.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if errors.Is(err, shortener.ErrForbidden) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("failed to list links", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := ListLinksResponse{
+		Items:      make([]LinkResponse, 0, len(page.Links)),
+		NextCursor: page.NextCursor,
+	}
+	for _, link := range page.Links {
+		resp.Items = append(resp.Items, h.toResponse(link))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetLink returns the details of a single link.
+func (h *LinksHandler) GetLink(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseLinkID(w, r)
+	if !ok {
+		return
+	}
+
+	link, err := h.service.GetLink(r.Context(), ownerID, linkID)
+	if err != nil {
+		h.writeError(w, r, "failed to get link", linkID, err)
+		return
+	}
+
+	h.writeLink(w, link)
+}
+
+// UpdateLink changes the destination URL and/or active flag of a link.
+func (h *LinksHandler) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseLinkID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to decode request body", logger.Error(err))
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.service.UpdateLink(r.Context(), ownerID, linkID, shortener.UpdateLinkParams{
+		OriginalURL: req.OriginalURL,
+		IsActive:    req.IsActive,
+	})
+	if err != nil {
+		h.writeError(w, r, "failed to update link", linkID, err)
+		return
+	}
+
+	h.logger.Info("link updated", logger.Int64("link_id", linkID))
+	h.writeLink(w, link)
+}
+
+// DeleteLink removes a link.
+func (h *LinksHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseLinkID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLink(r.Context(), ownerID, linkID); err != nil {
+		h.writeError(w, r, "failed to delete link", linkID, err)
+		return
+	}
+
+	h.logger.Info("link deleted", logger.Int64("link_id", linkID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TransferLink moves a link into another workspace.
+func (h *LinksHandler) TransferLink(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseLinkID(w, r)
+	if !ok {
+		return
+	}
+
+	var req TransferLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to decode request body", logger.Error(err))
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.WorkspaceID <= 0 {
+		http.Error(w, "invalid workspace_id", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.service.TransferLink(r.Context(), ownerID, linkID, req.WorkspaceID)
+	if err != nil {
+		h.writeError(w, r, "failed to transfer link", linkID, err)
+		return
+	}
+
+	h.logger.Info("link transferred",
+		logger.Int64("link_id", linkID),
+		logger.Int64("workspace_id", req.WorkspaceID),
+	)
+	h.writeLink(w, link)
+}
+
+// toResponse converts a link into its API representation.
+func (h *LinksHandler) toResponse(link *shortener.Link) LinkResponse {
+	return LinkResponse{
+		ID:          strconv.FormatInt(link.ID, 10),
+		WorkspaceID: link.WorkspaceID,
+		ShortCode:   link.ShortCode,
+		ShortURL:    h.service.BuildShortURL(link),
+		OriginalURL: link.OriginalURL,
+		IsActive:    link.IsActive,
+		ExpiresAt:   link.ExpiresAt,
+		CreatedAt:   link.CreatedAt,
+	}
+}
+
+// writeLink encodes a link as a LinkResponse.
+func (h *LinksHandler) writeLink(w http.ResponseWriter, link *shortener.Link) {
+	resp := h.toResponse(link)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeError maps service errors to HTTP responses.
+func (h *LinksHandler) writeError(w http.ResponseWriter, r *http.Request, msg string, linkID int64, err error) {
+	switch {
+	case errors.Is(err, shortener.ErrNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, shortener.ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case errors.Is(err, shortener.ErrInvalidUpdate), errors.Is(err, shortener.ErrInvalidURL):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg,
+			logger.Int64("link_id", linkID),
+			logger.Error(err),
+		)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+// parseLinkID extracts the {id} route parameter, answering 400 when it is missing or malformed.
+func parseLinkID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		http.Error(w, "missing link ID", http.StatusBadRequest)
+		return 0, false
+	}
+
+	linkID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || linkID <= 0 {
+		http.Error(w, "invalid link ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return linkID, true
+}
@@ -3,13 +3,17 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/kafka"
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/scan"
 	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -17,14 +21,19 @@ import (
 type RedirectHandler struct {
 	service        shortener.Service
 	clicksProducer kafka.ClickProducer
+	scanGuard      *scan.Guard
+	metrics        *metrics.APIMetrics
 	logger         logger.Logger
 }
 
 // NewRedirectHandler constructs a redirect handler using the provided services.
-func NewRedirectHandler(service shortener.Service, clicksProducer kafka.ClickProducer, logger logger.Logger) *RedirectHandler {
+// Scan detection is disabled when scanGuard is nil.
+func NewRedirectHandler(service shortener.Service, clicksProducer kafka.ClickProducer, scanGuard *scan.Guard, apiMetrics *metrics.APIMetrics, logger logger.Logger) *RedirectHandler {
 	return &RedirectHandler{
 		service:        service,
 		clicksProducer: clicksProducer,
+		scanGuard:      scanGuard,
+		metrics:        apiMetrics,
 		logger:         logger,
 	}
 }
@@ -44,29 +53,52 @@ func (h *RedirectHandler) Redirect(w http.ResponseWriter, r *http.Request) {
 		scheme = "https"
 	}
 	domain := fmt.Sprintf("%s://%s", scheme, r.Host)
+	ip := clientIP(r)
+
+	if !h.admit(w, r, ip) {
+		return
+	}
 
 	link, err := h.service.ResolveLink(ctx, domain, code)
 	if err != nil {
-		if errors.Is(err, shortener.ErrNotFound) {
+		switch {
+		case errors.Is(err, shortener.ErrNotFound):
+			h.recordMiss(r, ip)
+			h.metrics.RedirectsTotal.WithLabelValues("not_found").Inc()
 			h.logger.Info("link not found",
 				logger.String("domain", domain),
 				logger.String("code", code),
 			)
 			http.NotFound(w, r)
-			return
+		case errors.Is(err, shortener.ErrExpired):
+			h.metrics.RedirectsTotal.WithLabelValues("expired").Inc()
+			h.logger.Info("link expired",
+				logger.String("domain", domain),
+				logger.String("code", code),
+			)
+			http.Error(w, "link expired", http.StatusGone)
+		case errors.Is(err, shortener.ErrDisabled):
+			h.recordMiss(r, ip)
+			h.metrics.RedirectsTotal.WithLabelValues("disabled").Inc()
+			h.logger.Info("link disabled",
+				logger.String("domain", domain),
+				logger.String("code", code),
+			)
+			http.NotFound(w, r)
+		default:
+			h.metrics.RedirectsTotal.WithLabelValues("error").Inc()
+			h.logger.Error("failed to resolve link",
+				logger.Error(err),
+				logger.String("domain", domain),
+				logger.String("code", code),
+			)
+			http.Error(w, "failed to resolve link", http.StatusInternalServerError)
 		}
-		h.logger.Error("failed to resolve link", logger.Error(err),
-			logger.Error(err),
-			logger.String("domain", domain),
-			logger.String("code", code),
-		)
-		http.Error(w, "failed to resolve link", http.StatusInternalServerError)
 		return
 	}
 
 	ua := r.UserAgent()
 	referer := r.Referer()
-	ip := clientIP(r)
 	clickedAt := time.Now().UTC()
 
 	event := kafka.NewClickEvent(
@@ -92,9 +124,64 @@ func (h *RedirectHandler) Redirect(w http.ResponseWriter, r *http.Request) {
 		logger.String("original_url", link.OriginalURL),
 		logger.String("ip", ip),
 	)
+	h.metrics.RedirectsTotal.WithLabelValues("ok").Inc()
 	http.Redirect(w, r, link.OriginalURL, http.StatusTemporaryRedirect)
 }
 
+// admit applies scan detection to the request: blocked clients get 429, tarpitted ones are
+// delayed. It reports whether the request should be served. Detection failures let it through.
+func (h *RedirectHandler) admit(w http.ResponseWriter, r *http.Request, ip string) bool {
+	if h.scanGuard == nil {
+		return true
+	}
+
+	decision, err := h.scanGuard.Admit(r.Context(), ip)
+	if err != nil {
+		h.logger.Warn("failed to check scan block", logger.Error(err), logger.String("ip", ip))
+		return true
+	}
+
+	if decision.Blocked {
+		h.metrics.ScanThrottledTotal.WithLabelValues(string(scan.ModeBlock)).Inc()
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(decision.RetryAfter.Seconds())), 10))
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return false
+	}
+
+	if decision.Delay > 0 {
+		h.metrics.ScanThrottledTotal.WithLabelValues(string(scan.ModeTarpit)).Inc()
+		t := time.NewTimer(decision.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			return false
+		}
+	}
+	return true
+}
+
+// recordMiss counts a not-found lookup towards scan detection and reports newly flagged clients.
+func (h *RedirectHandler) recordMiss(r *http.Request, ip string) {
+	if h.scanGuard == nil {
+		return
+	}
+
+	flagged, err := h.scanGuard.RecordMiss(r.Context(), ip)
+	if err != nil {
+		h.logger.Warn("failed to record scan miss", logger.Error(err), logger.String("ip", ip))
+		return
+	}
+	if flagged {
+		h.metrics.ScanDetectionsTotal.Inc()
+		h.logger.Warn("short code scan detected",
+			logger.String("ip", ip),
+			logger.String("mode", string(h.scanGuard.Mode())),
+			logger.String("user_agent", r.UserAgent()),
+		)
+	}
+}
+
 func clientIP(r *http.Request) string {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
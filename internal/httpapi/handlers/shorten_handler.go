@@ -1,38 +1,102 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
+	"github.com/PavelKhromykhGo/url-shortener/internal/idempotency"
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
 	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
 )
 
+const (
+	// maxShortenBodyBytes caps the single-link request body, which is also buffered for idempotency hashing.
+	maxShortenBodyBytes = 64 << 10
+	// maxBatchBodyBytes caps the bulk request body.
+	maxBatchBodyBytes = 8 << 20
+	// maxTTLSeconds is the largest ttl that still fits in a time.Duration.
+	maxTTLSeconds = math.MaxInt64 / int64(time.Second)
+)
+
+// requestOwner returns the authenticated owner of the request, answering 401 when there is none.
+func requestOwner(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	ownerID, ok := auth.OwnerFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	return ownerID, true
+}
+
 // ShortenRequest represents payload for creating a new short link.
 type ShortenRequest struct {
-	URL string `json:"url"`
+	URL       string     `json:"url"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// TTL is the link lifetime in seconds.
+	TTL int64 `json:"ttl,omitempty"`
+	// Dedup returns the owner's existing link for the same URL instead of creating a new one.
+	// It is honored by the single-link endpoint only.
+	Dedup bool `json:"dedup,omitempty"`
+	// WorkspaceID creates the link in a workspace instead of the caller's personal space.
+	WorkspaceID *int64 `json:"workspace_id,omitempty"`
 }
 
 // ShortenResponse describes the response containing the generated short link details.
 type ShortenResponse struct {
-	ID          string `json:"id"`
-	ShortCode   string `json:"short_code"`
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	ID          string     `json:"id"`
+	WorkspaceID *int64     `json:"workspace_id,omitempty"`
+	ShortCode   string     `json:"short_code"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// BatchShortenRequest represents payload for creating many short links at once.
+type BatchShortenRequest struct {
+	Items []ShortenRequest `json:"items"`
+}
+
+// BatchShortenResult describes the outcome of one batch item: either the created link or an error.
+type BatchShortenResult struct {
+	Index int `json:"index"`
+	*ShortenResponse
+	Error string `json:"error,omitempty"`
+}
+
+// BatchShortenResponse lists per-item results in request order.
+type BatchShortenResponse struct {
+	Items []BatchShortenResult `json:"items"`
 }
 
 // ShortenHandler handles creation of shortened URLs.
 type ShortenHandler struct {
-	service shortener.Service
-	logger  logger.Logger
+	service        shortener.Service
+	idempotency    idempotency.Store
+	idempotencyTTL time.Duration
+	logger         logger.Logger
 }
 
+// maxIdempotencyKeyLength bounds the accepted Idempotency-Key header value.
+const maxIdempotencyKeyLength = 255
+
 // NewShortenHandler constructs a handler that delegates short link creation to the provided service.
-func NewShortenHandler(service shortener.Service, logger logger.Logger) *ShortenHandler {
+// Idempotency-Key support is disabled when idempotencyStore is nil.
+func NewShortenHandler(service shortener.Service, idempotencyStore idempotency.Store, idempotencyTTL time.Duration, logger logger.Logger) *ShortenHandler {
 	return &ShortenHandler{
-		service: service,
-		logger:  logger,
+		service:        service,
+		idempotency:    idempotencyStore,
+		idempotencyTTL: idempotencyTTL,
+		logger:         logger,
 	}
 }
 
@@ -40,8 +104,24 @@ func NewShortenHandler(service shortener.Service, logger logger.Logger) *Shorten
 func (h *ShortenHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxShortenBodyBytes))
+	if err != nil {
+		if isBodyTooLarge(err) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Warn("failed to read request body", logger.Error(err))
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
 	var req ShortenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		h.logger.Warn("failed to decode request body", logger.Error(err))
 		http.Error(w, "invalid JSON body", http.StatusBadRequest)
 		return
@@ -51,21 +131,184 @@ func (h *ShortenHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid URL", http.StatusBadRequest)
 		return
 	}
+	if msg := req.validateTTL(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
 
-	const fakeOwnerID int64 = 1
+	key := r.Header.Get(idempotency.HeaderName)
+	if h.idempotency == nil {
+		key = ""
+	}
+	if key != "" && !h.reserveIdempotencyKey(w, r, ownerID, key, body) {
+		return
+	}
 
-	link, err := h.service.CreateShortLink(ctx, fakeOwnerID, req.URL)
+	link, err := h.service.CreateShortLink(ctx, ownerID, req.params())
 	if err != nil {
+		h.releaseIdempotencyKey(ctx, ownerID, key)
+		if writeQuotaError(w, err) {
+			return
+		}
+		if status, msg, ok := createErrorStatus(err); ok {
+			http.Error(w, msg, status)
+			return
+		}
 		h.logger.Error("failed to create short link", logger.Error(err))
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	resp := ShortenResponse{
-		ID:          strconv.FormatInt(link.ID, 10),
-		ShortCode:   link.ShortCode,
-		ShortURL:    h.service.BuildShortURL(link),
-		OriginalURL: link.OriginalURL,
+	data, err := json.Marshal(h.toResponse(link))
+	if err != nil {
+		h.releaseIdempotencyKey(ctx, ownerID, key)
+		h.logger.Error("failed to encode response", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	data = append(data, '\n')
+
+	h.completeIdempotencyKey(ctx, ownerID, key, http.StatusOK, data)
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		h.logger.Warn("failed to write response", logger.Error(err))
+	}
+}
+
+// reserveIdempotencyKey claims key for this request. When the key was already used it
+// replays the stored response or answers with a conflict, and reports false.
+func (h *ShortenHandler) reserveIdempotencyKey(w http.ResponseWriter, r *http.Request, ownerID int64, key string, body []byte) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		http.Error(w, fmt.Sprintf("%s must not exceed %d characters", idempotency.HeaderName, maxIdempotencyKeyLength), http.StatusBadRequest)
+		return false
+	}
+
+	hash := idempotency.HashRequest(body)
+	rec, reserved, err := h.idempotency.Reserve(r.Context(), ownerID, key, hash, time.Now().Add(h.idempotencyTTL))
+	if err != nil {
+		h.logger.Error("failed to reserve idempotency key", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if reserved {
+		return true
+	}
+
+	switch {
+	case rec.RequestHash != hash:
+		http.Error(w, idempotency.HeaderName+" was already used with a different request", http.StatusUnprocessableEntity)
+	case !rec.Completed():
+		http.Error(w, "a request with this "+idempotency.HeaderName+" is still in progress", http.StatusConflict)
+	default:
+		h.logger.Info("replaying idempotent response", logger.String("idempotency_key", key))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(rec.StatusCode)
+		if _, err := w.Write(rec.Response); err != nil {
+			h.logger.Warn("failed to write response", logger.Error(err))
+		}
+	}
+	return false
+}
+
+// completeIdempotencyKey stores the response for key so retries can replay it.
+func (h *ShortenHandler) completeIdempotencyKey(ctx context.Context, ownerID int64, key string, status int, data []byte) {
+	if key == "" {
+		return
+	}
+	if err := h.idempotency.Complete(context.WithoutCancel(ctx), ownerID, key, status, data); err != nil {
+		h.logger.Error("failed to store idempotent response",
+			logger.Error(err),
+			logger.String("idempotency_key", key),
+		)
+	}
+}
+
+// releaseIdempotencyKey frees key after a failed request so the client can retry with it.
+func (h *ShortenHandler) releaseIdempotencyKey(ctx context.Context, ownerID int64, key string) {
+	if key == "" {
+		return
+	}
+	if err := h.idempotency.Release(context.WithoutCancel(ctx), ownerID, key); err != nil {
+		h.logger.Warn("failed to release idempotency key",
+			logger.Error(err),
+			logger.String("idempotency_key", key),
+		)
+	}
+}
+
+// CreateLinks creates short URLs for every item of the JSON payload.
+// Item failures are reported per item and do not fail the whole request.
+func (h *ShortenHandler) CreateLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req BatchShortenRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&req); err != nil {
+		if isBodyTooLarge(err) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Warn("failed to decode request body", logger.Error(err))
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > shortener.MaxBatchSize {
+		http.Error(w, fmt.Sprintf("items must contain 1..%d entries", shortener.MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	resp := BatchShortenResponse{
+		Items: make([]BatchShortenResult, len(req.Items)),
+	}
+
+	params := make([]shortener.CreateLinkParams, 0, len(req.Items))
+	paramsIdx := make([]int, 0, len(req.Items))
+	for i, item := range req.Items {
+		resp.Items[i].Index = i
+		if item.URL == "" {
+			resp.Items[i].Error = "invalid URL"
+			continue
+		}
+		if msg := item.validateTTL(); msg != "" {
+			resp.Items[i].Error = msg
+			continue
+		}
+		params = append(params, item.params())
+		paramsIdx = append(paramsIdx, i)
+	}
+
+	if len(params) > 0 {
+		results, err := h.service.CreateShortLinks(ctx, ownerID, params)
+		if err != nil {
+			if writeQuotaError(w, err) {
+				return
+			}
+			h.logger.Error("failed to create short links", logger.Error(err))
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		for j, res := range results {
+			item := &resp.Items[paramsIdx[j]]
+			if res.Err != nil {
+				if _, msg, ok := createErrorStatus(res.Err); ok {
+					item.Error = msg
+					continue
+				}
+				h.logger.Error("failed to create short link in batch", logger.Error(res.Err))
+				item.Error = "internal server error"
+				continue
+			}
+			link := h.toResponse(res.Link)
+			item.ShortenResponse = &link
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -74,5 +317,62 @@ func (h *ShortenHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
+}
 
+// toResponse converts a created link into its API representation.
+func (h *ShortenHandler) toResponse(link *shortener.Link) ShortenResponse {
+	return ShortenResponse{
+		ID:          strconv.FormatInt(link.ID, 10),
+		WorkspaceID: link.WorkspaceID,
+		ShortCode:   link.ShortCode,
+		ShortURL:    h.service.BuildShortURL(link),
+		OriginalURL: link.OriginalURL,
+		ExpiresAt:   link.ExpiresAt,
+	}
+}
+
+// validateTTL reports why the requested ttl cannot be converted to a duration, or "" when it can.
+func (req ShortenRequest) validateTTL() string {
+	if req.TTL < 0 || req.TTL > maxTTLSeconds {
+		return fmt.Sprintf("ttl must be between 0 and %d seconds", maxTTLSeconds)
+	}
+	return ""
+}
+
+// isBodyTooLarge reports whether reading the body hit the http.MaxBytesReader limit.
+func isBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// params converts the request payload into service creation parameters.
+func (req ShortenRequest) params() shortener.CreateLinkParams {
+	return shortener.CreateLinkParams{
+		OriginalURL: req.URL,
+		Alias:       req.Alias,
+		ExpiresAt:   req.ExpiresAt,
+		TTL:         time.Duration(req.TTL) * time.Second,
+		Dedup:       req.Dedup,
+		WorkspaceID: req.WorkspaceID,
+	}
+}
+
+// createErrorStatus maps client-caused creation errors to an HTTP status and message.
+// It reports false for errors that should be treated as internal failures.
+func createErrorStatus(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, shortener.ErrInvalidURL),
+		errors.Is(err, shortener.ErrInvalidAlias),
+		errors.Is(err, shortener.ErrInvalidExpiration):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, shortener.ErrAlreadyExists):
+		return http.StatusConflict, "short code is already taken", true
+	case errors.Is(err, shortener.ErrNotFound):
+		return http.StatusNotFound, "workspace not found", true
+	case errors.Is(err, shortener.ErrForbidden):
+		return http.StatusForbidden, "forbidden", true
+	case errors.Is(err, quota.ErrLimitExceeded):
+		return http.StatusTooManyRequests, err.Error(), true
+	}
+	return 0, "", false
 }
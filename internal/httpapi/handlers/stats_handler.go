@@ -2,13 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/analytics"
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
-	"github.com/go-chi/chi/v5"
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
 )
 
 // DailyStatItem represents a single day's aggregated click count.
@@ -43,15 +43,13 @@ func NewStatsHandler(analyticsService analytics.Service, logger logger.Logger) *
 func (h *StatsHandler) GetDailyStats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		http.Error(w, "missing link ID", http.StatusBadRequest)
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
 		return
 	}
 
-	linkID, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil || linkID <= 0 {
-		http.Error(w, "invalid link ID", http.StatusBadRequest)
+	linkID, ok := parseLinkID(w, r)
+	if !ok {
 		return
 	}
 
@@ -59,7 +57,10 @@ func (h *StatsHandler) GetDailyStats(w http.ResponseWriter, r *http.Request) {
 	fromStr := q.Get("from")
 	toStr := q.Get("to")
 
-	var from, to time.Time
+	var (
+		from, to time.Time
+		err      error
+	)
 	const layout = "2006-01-02"
 
 	if fromStr == "" && toStr == "" {
@@ -85,8 +86,16 @@ func (h *StatsHandler) GetDailyStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stats, err := h.analyticsService.GetDailyStats(ctx, linkID, from, to)
+	stats, err := h.analyticsService.GetDailyStats(ctx, ownerID, linkID, from, to)
 	if err != nil {
+		if errors.Is(err, shortener.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if errors.Is(err, shortener.ErrForbidden) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
 		h.logger.Error("failed to get daily stats",
 			logger.Int64("link_id", linkID),
 			logger.Error(err),
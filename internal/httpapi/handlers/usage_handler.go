@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
+)
+
+// UsageResponse describes the caller's plan and consumption in the current period.
+// Null limits mean unlimited.
+type UsageResponse struct {
+	Plan           string    `json:"plan"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	LinksCreated   int64     `json:"links_created"`
+	LinksLimit     *int64    `json:"links_limit"`
+	LinksRemaining *int64    `json:"links_remaining"`
+	MaxBatchSize   *int      `json:"max_batch_size"`
+}
+
+// QuotaErrorResponse is the machine-readable body returned when a plan limit is hit.
+type QuotaErrorResponse struct {
+	Error   string     `json:"error"`
+	Message string     `json:"message"`
+	Plan    string     `json:"plan"`
+	Limit   int64      `json:"limit"`
+	Used    int64      `json:"used"`
+	ResetAt *time.Time `json:"reset_at,omitempty"`
+}
+
+// UsageHandler serves quota usage requests.
+type UsageHandler struct {
+	service quota.Service
+	logger  logger.Logger
+}
+
+// NewUsageHandler constructs a handler that reports usage from the provided quota service.
+func NewUsageHandler(service quota.Service, logger logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetUsage returns the caller's plan limits and current consumption.
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := h.service.GetUsage(r.Context(), ownerID)
+	if err != nil {
+		h.logger.Error("failed to get usage", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := UsageResponse{
+		Plan:         usage.Plan.Name,
+		PeriodStart:  usage.PeriodStart,
+		PeriodEnd:    usage.PeriodEnd,
+		LinksCreated: usage.LinksCreated,
+	}
+	if limit := usage.Plan.MonthlyLinks; limit > 0 {
+		remaining := max(limit-usage.LinksCreated, 0)
+		resp.LinksLimit = &limit
+		resp.LinksRemaining = &remaining
+	}
+	if size := usage.Plan.MaxBatchSize; size > 0 {
+		resp.MaxBatchSize = &size
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeQuotaError answers with a machine-readable error if err is a plan limit violation:
+// 429 with Retry-After for the monthly limit and 403 for per-request limits.
+// It reports whether a response was written.
+func writeQuotaError(w http.ResponseWriter, err error) bool {
+	var limitErr *quota.LimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	resp := QuotaErrorResponse{
+		Error:   limitErr.Code,
+		Message: limitErr.Error(),
+		Plan:    limitErr.Plan,
+		Limit:   limitErr.Limit,
+		Used:    limitErr.Used,
+	}
+
+	status := http.StatusForbidden
+	if limitErr.Code == quota.CodeBatchSize {
+		resp.Used = limitErr.Requested
+	}
+	if !limitErr.ResetAt.IsZero() {
+		status = http.StatusTooManyRequests
+		resp.ResetAt = &limitErr.ResetAt
+		retryAfter := int64(time.Until(limitErr.ResetAt).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+	return true
+}
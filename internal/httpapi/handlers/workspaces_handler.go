@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateWorkspaceRequest represents payload for creating a workspace.
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// WorkspaceResponse describes a workspace and the caller's role in it.
+type WorkspaceResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetMemberRequest represents payload for adding a member or changing their role.
+type SetMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// MemberResponse describes a workspace membership.
+type MemberResponse struct {
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspacesHandler serves workspace and membership management requests.
+type WorkspacesHandler struct {
+	service workspace.Service
+	logger  logger.Logger
+}
+
+// NewWorkspacesHandler constructs a handler that delegates workspace management to the provided service.
+func NewWorkspacesHandler(service workspace.Service, logger logger.Logger) *WorkspacesHandler {
+	return &WorkspacesHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateWorkspace creates a workspace with the caller as admin.
+func (h *WorkspacesHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to decode request body", logger.Error(err))
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	ws, err := h.service.CreateWorkspace(r.Context(), userID, req.Name)
+	if err != nil {
+		h.writeError(w, r, "failed to create workspace", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	h.writeJSON(w, toWorkspaceResponse(*ws))
+}
+
+// ListWorkspaces returns the workspaces the caller belongs to.
+func (h *WorkspacesHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.ListWorkspaces(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, "failed to list workspaces", err)
+		return
+	}
+
+	items := make([]WorkspaceResponse, 0, len(list))
+	for _, ws := range list {
+		items = append(items, toWorkspaceResponse(ws))
+	}
+	h.writeJSON(w, items)
+}
+
+// ListMembers returns the members of a workspace.
+func (h *WorkspacesHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+	workspaceID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(r.Context(), userID, workspaceID)
+	if err != nil {
+		h.writeError(w, r, "failed to list workspace members", err)
+		return
+	}
+
+	items := make([]MemberResponse, 0, len(members))
+	for _, m := range members {
+		items = append(items, toMemberResponse(m))
+	}
+	h.writeJSON(w, items)
+}
+
+// SetMember adds a user to a workspace or changes their role.
+func (h *WorkspacesHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+	workspaceID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var req SetMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to decode request body", logger.Error(err))
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	m, err := h.service.SetMember(r.Context(), userID, workspaceID, memberID, workspace.Role(req.Role))
+	if err != nil {
+		h.writeError(w, r, "failed to set workspace member", err)
+		return
+	}
+
+	h.writeJSON(w, toMemberResponse(*m))
+}
+
+// RemoveMember removes a user from a workspace.
+func (h *WorkspacesHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+	workspaceID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := parseIDParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(r.Context(), userID, workspaceID, memberID); err != nil {
+		h.writeError(w, r, "failed to remove workspace member", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON encodes v as the JSON response body.
+func (h *WorkspacesHandler) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode response", logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeError maps workspace service errors to HTTP responses.
+func (h *WorkspacesHandler) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	switch {
+	case errors.Is(err, workspace.ErrNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, workspace.ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case errors.Is(err, workspace.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, logger.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+func toWorkspaceResponse(ws workspace.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        ws.ID,
+		Name:      ws.Name,
+		Role:      string(ws.Role),
+		CreatedAt: ws.CreatedAt,
+	}
+}
+
+func toMemberResponse(m workspace.Member) MemberResponse {
+	return MemberResponse{
+		UserID:    m.UserID,
+		Role:      string(m.Role),
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// parseIDParam extracts a positive integer route parameter, answering 400 when it is missing or malformed.
+func parseIDParam(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	v := chi.URLParam(r, name)
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
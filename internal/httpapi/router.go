@@ -2,13 +2,21 @@ package httpapi
 
 import (
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/analytics"
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
 	"github.com/PavelKhromykhGo/url-shortener/internal/httpapi/handlers"
+	"github.com/PavelKhromykhGo/url-shortener/internal/idempotency"
 	"github.com/PavelKhromykhGo/url-shortener/internal/kafka"
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
+	"github.com/PavelKhromykhGo/url-shortener/internal/ratelimit"
+	"github.com/PavelKhromykhGo/url-shortener/internal/scan"
 	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -20,6 +28,17 @@ type Deps struct {
 	ShortenerService shortener.Service
 	ClicksProducer   kafka.ClickProducer
 	AnalyticsService analytics.Service
+	WorkspaceService workspace.Service
+	QuotaService     quota.Service
+	APIMetrics       *metrics.APIMetrics
+	IdempotencyStore idempotency.Store
+	IdempotencyTTL   time.Duration
+	Authenticators   []auth.Authenticator
+	RateLimiter      ratelimit.Limiter
+	RateLimits       RateLimits
+	ScanGuard        *scan.Guard
+	// Ready gates /readyz; a nil value means the API is always ready.
+	Ready *atomic.Bool
 }
 
 // NewRouter configures the chi router with middleware, metrics, and all public routes.
@@ -42,26 +61,75 @@ func NewRouter(d Deps) http.Handler {
 		_, _ = w.Write([]byte("ok"))
 	})
 
+	r.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if d.Ready != nil && !d.Ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+
 	r.Handle("/metrics", promhttp.Handler())
 
 	r.Route("/api/v1", func(api chi.Router) {
+		// Requests are limited per IP before authentication, which costs a lookup per credential,
+		// and per owner after it.
+		api.Use(NewRateLimitMiddleware(d.RateLimiter, "api_ip", d.RateLimits.APIPerIP, d.APIMetrics, d.Logger))
+		api.Use(NewAuthMiddleware(d.Logger, d.Authenticators...))
+		api.Use(NewRateLimitMiddleware(d.RateLimiter, "api", d.RateLimits.API, d.APIMetrics, d.Logger))
+
 		shortenHandler := handlers.NewShortenHandler(
 			d.ShortenerService,
+			d.IdempotencyStore,
+			d.IdempotencyTTL,
 			d.Logger,
 		)
-		api.Post("/shorten", shortenHandler.CreateLink)
+		shortenLimit := NewRateLimitMiddleware(d.RateLimiter, "shorten", d.RateLimits.Shorten, d.APIMetrics, d.Logger)
+		api.With(shortenLimit).Post("/shorten", shortenHandler.CreateLink)
+		api.With(shortenLimit).Post("/shorten/batch", shortenHandler.CreateLinks)
+
+		linksHandler := handlers.NewLinksHandler(
+			d.ShortenerService,
+			d.Logger,
+		)
+		api.Get("/links", linksHandler.ListLinks)
+		api.Get("/links/{id}", linksHandler.GetLink)
+		api.Patch("/links/{id}", linksHandler.UpdateLink)
+		api.Delete("/links/{id}", linksHandler.DeleteLink)
+		api.Post("/links/{id}/transfer", linksHandler.TransferLink)
+
+		workspacesHandler := handlers.NewWorkspacesHandler(
+			d.WorkspaceService,
+			d.Logger,
+		)
+		api.Post("/workspaces", workspacesHandler.CreateWorkspace)
+		api.Get("/workspaces", workspacesHandler.ListWorkspaces)
+		api.Get("/workspaces/{id}/members", workspacesHandler.ListMembers)
+		api.Put("/workspaces/{id}/members/{userID}", workspacesHandler.SetMember)
+		api.Delete("/workspaces/{id}/members/{userID}", workspacesHandler.RemoveMember)
+
 		statsHandler := handlers.NewStatsHandler(
 			d.AnalyticsService,
 			d.Logger,
 		)
 		api.Get("/links/{id}/stats/daily", statsHandler.GetDailyStats)
+
+		usageHandler := handlers.NewUsageHandler(
+			d.QuotaService,
+			d.Logger,
+		)
+		api.Get("/usage", usageHandler.GetUsage)
 	})
 	redirectHandler := handlers.NewRedirectHandler(
 		d.ShortenerService,
 		d.ClicksProducer,
+		d.ScanGuard,
+		d.APIMetrics,
 		d.Logger,
 	)
-	r.Get("/{code}", redirectHandler.Redirect)
+	r.With(NewRateLimitMiddleware(d.RateLimiter, "redirect", d.RateLimits.Redirect, d.APIMetrics, d.Logger)).
+		Get("/{code}", redirectHandler.Redirect)
 	return r
 }
 
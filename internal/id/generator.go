@@ -3,8 +3,12 @@ package id
 import (
 	"crypto/rand"
 	"fmt"
+	"io"
 )
 
+// alphabet is the base62 character set used for short codes.
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
 // Generator produces short codes for shortened URLs.
 type Generator interface {
 	GenerateShortCode() (string, error)
@@ -14,28 +18,40 @@ type Generator interface {
 type RandomGenerator struct {
 	length       int
 	allowedChars []byte
+	// random is the source of random bytes, crypto/rand outside tests.
+	random io.Reader
 }
 
 // NewRandomGenerator creates a generator that builds codes with the given length.
 func NewRandomGenerator(length int) *RandomGenerator {
-	allowed := []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 	return &RandomGenerator{
 		length:       length,
-		allowedChars: allowed,
+		allowedChars: []byte(alphabet),
+		random:       rand.Reader,
 	}
 }
 
 // GenerateShortCode produces a new random short code.
+// Bytes at or above the largest multiple of the alphabet size are rejected,
+// so every character is equally likely.
 func (g *RandomGenerator) GenerateShortCode() (string, error) {
-	b := make([]byte, g.length)
-
-	_, err := rand.Read(b)
-	if err != nil {
-		return "", fmt.Errorf("crypto rand read failed %w", err)
-	}
+	limit := 256 - 256%len(g.allowedChars)
 
-	for i := range b {
-		b[i] = g.allowedChars[int(b[i])%len(g.allowedChars)]
+	code := make([]byte, 0, g.length)
+	buf := make([]byte, g.length+g.length/4+1)
+	for len(code) < g.length {
+		if _, err := io.ReadFull(g.random, buf); err != nil {
+			return "", fmt.Errorf("crypto rand read failed %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, g.allowedChars[int(b)%len(g.allowedChars)])
+			if len(code) == g.length {
+				break
+			}
+		}
 	}
-	return string(b), nil
+	return string(code), nil
 }
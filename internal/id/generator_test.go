@@ -0,0 +1,57 @@
+package id
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRandomGeneratorRejectsBiasedBytes(t *testing.T) {
+	// Bytes 248..255 would wrap onto the first eight characters; they must be skipped.
+	src := []byte{248, 0, 255, 61, 250, 62, 251, 247, 252, 123, 253, 1, 254, 2, 249, 3}
+	want := string([]byte{alphabet[0], alphabet[61], alphabet[0], alphabet[247%62], alphabet[123%62], alphabet[1], alphabet[2], alphabet[3]})
+
+	g := NewRandomGenerator(8)
+	g.random = bytes.NewReader(append(src, bytes.Repeat([]byte{0}, 64)...))
+
+	got, err := g.GenerateShortCode()
+	if err != nil {
+		t.Fatalf("GenerateShortCode() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("GenerateShortCode() = %q, want %q", got, want)
+	}
+}
+
+func TestRandomGeneratorOnlyRejectedBytes(t *testing.T) {
+	g := NewRandomGenerator(4)
+	g.random = bytes.NewReader(bytes.Repeat([]byte{248, 255}, 64))
+
+	if code, err := g.GenerateShortCode(); err == nil {
+		t.Fatalf("GenerateShortCode() = %q, want error once the source is exhausted", code)
+	}
+}
+
+func TestRandomGeneratorCoversAlphabet(t *testing.T) {
+	g := NewRandomGenerator(16)
+	seen := make(map[rune]bool, len(alphabet))
+
+	for range 500 {
+		code, err := g.GenerateShortCode()
+		if err != nil {
+			t.Fatalf("GenerateShortCode() error = %v", err)
+		}
+		if len(code) != 16 {
+			t.Fatalf("len(%q) = %d, want 16", code, len(code))
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(alphabet, c) {
+				t.Fatalf("code %q contains %q outside the alphabet", code, c)
+			}
+			seen[c] = true
+		}
+	}
+	if len(seen) != len(alphabet) {
+		t.Errorf("saw %d distinct characters, want %d", len(seen), len(alphabet))
+	}
+}
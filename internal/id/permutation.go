@@ -0,0 +1,65 @@
+package id
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// feistelRounds is the number of Feistel rounds; four rounds give a pseudorandom permutation.
+const feistelRounds = 4
+
+// permutation is a keyed bijection over [0, n). It runs a balanced Feistel network over
+// the smallest even bit width covering n and walks the cycle until the value falls in range.
+type permutation struct {
+	n        uint64
+	halfBits uint
+	mask     uint64
+	key      [sha256.Size]byte
+}
+
+func newPermutation(key []byte, n uint64) (*permutation, error) {
+	if n < 2 {
+		return nil, errors.New("permutation domain must hold at least two values")
+	}
+
+	halfBits := uint(1)
+	for halfBits < 32 && uint64(1)<<(2*halfBits) < n {
+		halfBits++
+	}
+
+	return &permutation{
+		n:        n,
+		halfBits: halfBits,
+		mask:     uint64(1)<<halfBits - 1,
+		key:      sha256.Sum256(key),
+	}, nil
+}
+
+// apply maps x, which must be below n, to its image in [0, n).
+func (p *permutation) apply(x uint64) uint64 {
+	x = p.feistel(x)
+	for x >= p.n {
+		x = p.feistel(x)
+	}
+	return x
+}
+
+func (p *permutation) feistel(x uint64) uint64 {
+	left, right := x>>p.halfBits, x&p.mask
+	for round := range feistelRounds {
+		left, right = right, left^p.round(byte(round), right)
+	}
+	return left<<p.halfBits | right
+}
+
+// round is the Feistel round function: a keyed hash of the round number and the right half.
+func (p *permutation) round(round byte, half uint64) uint64 {
+	var in [sha256.Size + 9]byte
+	copy(in[:], p.key[:])
+	in[sha256.Size] = round
+	binary.BigEndian.PutUint64(in[sha256.Size+1:], half)
+
+	sum := sha256.Sum256(in[:])
+	return binary.BigEndian.Uint64(sum[:8]) & p.mask
+}
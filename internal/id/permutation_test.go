@@ -0,0 +1,118 @@
+package id
+
+import (
+	"context"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestPermutationIsBijection(t *testing.T) {
+	// Domains that are and are not powers of four exercise cycle walking.
+	for _, n := range []uint64{2, 3, 16, 62, 1000, 62 * 62 * 62} {
+		p, err := newPermutation(testKey, n)
+		if err != nil {
+			t.Fatalf("newPermutation(%d) error = %v", n, err)
+		}
+
+		seen := make([]bool, n)
+		for x := range n {
+			y := p.apply(x)
+			if y >= n {
+				t.Fatalf("n=%d: apply(%d) = %d, out of range", n, x, y)
+			}
+			if seen[y] {
+				t.Fatalf("n=%d: apply(%d) = %d, already produced", n, x, y)
+			}
+			seen[y] = true
+		}
+	}
+}
+
+func TestPermutationIsKeyed(t *testing.T) {
+	const n = 62 * 62 * 62 * 62
+	a, _ := newPermutation(testKey, n)
+	b, _ := newPermutation(testKey, n)
+	other, _ := newPermutation([]byte("another-secret-key"), n)
+
+	differs := 0
+	for x := range uint64(1000) {
+		if a.apply(x) != b.apply(x) {
+			t.Fatalf("same key: apply(%d) differs", x)
+		}
+		if a.apply(x) != other.apply(x) {
+			differs++
+		}
+	}
+	if differs < 990 {
+		t.Errorf("different keys agree on %d of 1000 values", 1000-differs)
+	}
+}
+
+func TestPermutationRejectsTinyDomain(t *testing.T) {
+	if _, err := newPermutation(testKey, 1); err == nil {
+		t.Error("newPermutation(1) error = nil, want error")
+	}
+}
+
+// counterSequence hands out consecutive IDs starting at 1.
+type counterSequence struct {
+	last int64
+}
+
+func (s *counterSequence) ReserveIDs(_ context.Context, n int) ([]int64, error) {
+	ids := make([]int64, n)
+	for i := range ids {
+		s.last++
+		ids[i] = s.last
+	}
+	return ids, nil
+}
+
+func TestSequenceGenerator(t *testing.T) {
+	g, err := NewSequenceGenerator(&counterSequence{}, testKey, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	prev := ""
+	// 62^3 - 1 codes: IDs start at 1, so the last one is still in range.
+	for range 62*62*62 - 1 {
+		code, err := g.GenerateShortCode()
+		if err != nil {
+			t.Fatalf("GenerateShortCode() error = %v", err)
+		}
+		if len(code) != 3 || seen[code] {
+			t.Fatalf("GenerateShortCode() = %q, want a new 3-character code", code)
+		}
+		if code == prev {
+			t.Fatalf("consecutive codes are equal: %q", code)
+		}
+		seen[code] = true
+		prev = code
+	}
+
+	if code, err := g.GenerateShortCode(); err == nil {
+		t.Fatalf("GenerateShortCode() = %q past the code space, want ErrSequenceExhausted", code)
+	}
+}
+
+func TestEncodeBase62(t *testing.T) {
+	tests := []struct {
+		v      uint64
+		length int
+		want   string
+	}{
+		{v: 0, length: 4, want: "aaaa"},
+		{v: 1, length: 4, want: "aaab"},
+		{v: 61, length: 2, want: "a9"},
+		{v: 62, length: 2, want: "ba"},
+		{v: 62*62 - 1, length: 2, want: "99"},
+	}
+	for _, tt := range tests {
+		if got := encodeBase62(tt.v, tt.length); got != tt.want {
+			t.Errorf("encodeBase62(%d, %d) = %q, want %q", tt.v, tt.length, got, tt.want)
+		}
+	}
+}
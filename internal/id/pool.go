@@ -0,0 +1,276 @@
+package id
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+)
+
+// poolTakeAttempts bounds how many times GenerateShortCode refills an empty pool
+// before giving up when concurrent callers keep draining it.
+const poolTakeAttempts = 3
+
+// CodeStore reserves short codes so that no two pools hand out the same code.
+type CodeStore interface {
+	// ReserveCodes reserves candidate codes for domain and returns the ones that were
+	// neither reserved by another pool nor already used by a link.
+	ReserveCodes(ctx context.Context, domain string, codes []string) ([]string, error)
+	// ReleaseCodes drops the reservations of codes that were never handed out.
+	ReleaseCodes(ctx context.Context, domain string, codes []string) error
+	// DeleteExpiredReservations drops reservations made before the given time.
+	DeleteExpiredReservations(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PoolConfig configures a Pool.
+type PoolConfig struct {
+	// Domain is the short link domain the codes are reserved for.
+	Domain string
+	// Size is how many codes the pool keeps buffered after a refill.
+	Size int
+	// RefillThreshold triggers a background refill once fewer codes are buffered.
+	RefillThreshold int
+	// ReservationTTL is how long a reservation protects a code. Buffered codes are
+	// dropped well before it, and older reservations are deleted so that codes
+	// leaked by crashed instances become available again.
+	ReservationTTL time.Duration
+}
+
+// Pool hands out short codes pre-generated by another Generator and reserved in a CodeStore
+// in batches, keeping code generation and most collision checks off the create path.
+// Run refills the pool in the background; Close returns unused codes to the store.
+type Pool struct {
+	gen    Generator
+	store  CodeStore
+	cfg    PoolConfig
+	logger logger.Logger
+	now    func() time.Time
+
+	refill chan struct{}
+	fillMu sync.Mutex
+
+	mu     sync.Mutex
+	codes  []pooledCode
+	closed bool
+}
+
+type pooledCode struct {
+	code       string
+	reservedAt time.Time
+}
+
+// NewPool creates a pool of codes produced by gen and reserved in store.
+func NewPool(gen Generator, store CodeStore, cfg PoolConfig, logger logger.Logger) (*Pool, error) {
+	if cfg.Size < 1 {
+		return nil, fmt.Errorf("code pool size must be positive, got %d", cfg.Size)
+	}
+	if cfg.RefillThreshold < 0 || cfg.RefillThreshold >= cfg.Size {
+		return nil, fmt.Errorf("code pool refill threshold must be in 0..%d, got %d", cfg.Size-1, cfg.RefillThreshold)
+	}
+	if cfg.ReservationTTL <= 0 {
+		return nil, errors.New("code pool reservation ttl must be positive")
+	}
+
+	return &Pool{
+		gen:    gen,
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		refill: make(chan struct{}, 1),
+	}, nil
+}
+
+// GenerateShortCode hands out the oldest buffered code. An empty pool is refilled inline;
+// a closed pool falls back to the underlying generator.
+func (p *Pool) GenerateShortCode() (string, error) {
+	for range poolTakeAttempts {
+		code, ok, closed := p.take()
+		if closed {
+			return p.gen.GenerateShortCode()
+		}
+		if ok {
+			return code, nil
+		}
+
+		metrics.ShortCodePoolEmptyTotal.Inc()
+		ctx, cancel := context.WithTimeout(context.Background(), reserveTimeout)
+		err := p.fill(ctx)
+		cancel()
+		if err != nil {
+			return "", fmt.Errorf("refill code pool: %w", err)
+		}
+	}
+	return "", errors.New("code pool is empty after refill")
+}
+
+// Run keeps the pool filled until ctx is canceled. It refills when the pool drops below
+// the threshold and periodically replaces codes whose reservations are getting old.
+func (p *Pool) Run(ctx context.Context) {
+	p.refillInBackground(ctx)
+
+	ticker := time.NewTicker(p.cfg.ReservationTTL / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.refill:
+			p.refillInBackground(ctx)
+		case <-ticker.C:
+			p.recycle(ctx)
+			p.refillInBackground(ctx)
+		}
+	}
+}
+
+// Close stops handing out buffered codes and releases their reservations.
+// Later GenerateShortCode calls use the underlying generator directly.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	codes := codeStrings(p.codes)
+	p.codes = nil
+	metrics.ShortCodePoolDepth.Set(0)
+	p.mu.Unlock()
+
+	if len(codes) == 0 {
+		return nil
+	}
+	if err := p.store.ReleaseCodes(ctx, p.cfg.Domain, codes); err != nil {
+		return fmt.Errorf("release %d pooled codes: %w", len(codes), err)
+	}
+	return nil
+}
+
+// take pops the oldest buffered code and requests a refill when the pool runs low.
+func (p *Pool) take() (code string, ok, closed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", false, true
+	}
+	if len(p.codes) == 0 {
+		return "", false, false
+	}
+
+	code = p.codes[0].code
+	p.codes = p.codes[1:]
+	metrics.ShortCodePoolDepth.Set(float64(len(p.codes)))
+
+	if len(p.codes) < p.cfg.RefillThreshold {
+		select {
+		case p.refill <- struct{}{}:
+		default:
+		}
+	}
+	return code, true, false
+}
+
+func (p *Pool) refillInBackground(ctx context.Context) {
+	if err := p.fill(ctx); err != nil && ctx.Err() == nil {
+		p.logger.Warn("failed to refill short code pool", logger.Error(err))
+	}
+}
+
+// fill generates candidates for the free slots of the pool and buffers the ones the store reserved.
+func (p *Pool) fill(ctx context.Context) error {
+	p.fillMu.Lock()
+	defer p.fillMu.Unlock()
+
+	p.mu.Lock()
+	need, closed := p.cfg.Size-len(p.codes), p.closed
+	p.mu.Unlock()
+	if closed || need <= 0 {
+		return nil
+	}
+
+	candidates := make([]string, 0, need)
+	seen := make(map[string]struct{}, need)
+	for attempt := 0; len(candidates) < need && attempt < 2*need; attempt++ {
+		code, err := p.gen.GenerateShortCode()
+		if err != nil {
+			return fmt.Errorf("generate short code: %w", err)
+		}
+		if _, dup := seen[code]; dup {
+			continue
+		}
+		seen[code] = struct{}{}
+		candidates = append(candidates, code)
+	}
+
+	reserved, err := p.store.ReserveCodes(ctx, p.cfg.Domain, candidates)
+	if err != nil {
+		return fmt.Errorf("reserve codes: %w", err)
+	}
+
+	now := p.now()
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return p.store.ReleaseCodes(ctx, p.cfg.Domain, reserved)
+	}
+	for _, code := range reserved {
+		p.codes = append(p.codes, pooledCode{code: code, reservedAt: now})
+	}
+	metrics.ShortCodePoolDepth.Set(float64(len(p.codes)))
+	p.mu.Unlock()
+
+	if skipped := len(candidates) - len(reserved); skipped > 0 {
+		p.logger.Debug("skipped taken short codes while refilling pool", logger.Int("count", skipped))
+	}
+	return nil
+}
+
+// recycle releases buffered codes reserved more than half a TTL ago, so that a code is
+// never handed out after its reservation could have expired, and deletes expired reservations.
+func (p *Pool) recycle(ctx context.Context) {
+	now := p.now()
+	cutoff := now.Add(-p.cfg.ReservationTTL / 2)
+
+	p.mu.Lock()
+	keep := p.codes[:0]
+	var stale []string
+	for _, c := range p.codes {
+		if c.reservedAt.Before(cutoff) {
+			stale = append(stale, c.code)
+			continue
+		}
+		keep = append(keep, c)
+	}
+	p.codes = keep
+	metrics.ShortCodePoolDepth.Set(float64(len(p.codes)))
+	p.mu.Unlock()
+
+	if len(stale) > 0 {
+		if err := p.store.ReleaseCodes(ctx, p.cfg.Domain, stale); err != nil {
+			p.logger.Warn("failed to release aged pooled codes",
+				logger.Error(err),
+				logger.Int("count", len(stale)),
+			)
+		}
+	}
+
+	deleted, err := p.store.DeleteExpiredReservations(ctx, now.Add(-p.cfg.ReservationTTL))
+	if err != nil {
+		p.logger.Warn("failed to delete expired code reservations", logger.Error(err))
+		return
+	}
+	if deleted > 0 {
+		p.logger.Info("deleted expired code reservations", logger.Int64("count", deleted))
+	}
+}
+
+func codeStrings(codes []pooledCode) []string {
+	out := make([]string, len(codes))
+	for i, c := range codes {
+		out[i] = c.code
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+package id
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...logger.Field) {}
+func (nopLogger) Info(string, ...logger.Field)  {}
+func (nopLogger) Warn(string, ...logger.Field)  {}
+func (nopLogger) Error(string, ...logger.Field) {}
+func (nopLogger) Fatal(string, ...logger.Field) {}
+
+// countingGenerator returns c0, c1, c2, ...
+type countingGenerator struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (g *countingGenerator) GenerateShortCode() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	code := fmt.Sprintf("c%d", g.n)
+	g.n++
+	return code, nil
+}
+
+// fakeCodeStore reserves every candidate and records releases. While gate is set,
+// ReserveCodes signals entered and waits for gate to be closed.
+type fakeCodeStore struct {
+	mu           sync.Mutex
+	reserved     []string
+	released     []string
+	deleteBefore []time.Time
+
+	gate    chan struct{}
+	entered chan struct{}
+}
+
+func (s *fakeCodeStore) ReserveCodes(ctx context.Context, _ string, codes []string) ([]string, error) {
+	s.mu.Lock()
+	gate, entered := s.gate, s.entered
+	s.mu.Unlock()
+	if gate != nil {
+		entered <- struct{}{}
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reserved = append(s.reserved, codes...)
+	return codes, nil
+}
+
+func (s *fakeCodeStore) ReleaseCodes(_ context.Context, _ string, codes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, codes...)
+	return nil
+}
+
+func (s *fakeCodeStore) DeleteExpiredReservations(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteBefore = append(s.deleteBefore, before)
+	return 0, nil
+}
+
+func (s *fakeCodeStore) block() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gate = make(chan struct{})
+	s.entered = make(chan struct{}, 1)
+}
+
+func (s *fakeCodeStore) unblock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.gate)
+	s.gate = nil
+}
+
+func (s *fakeCodeStore) snapshot() (reserved, released []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reserved), slices.Clone(s.released)
+}
+
+func newTestPool(t *testing.T, size, threshold int) (*Pool, *fakeCodeStore) {
+	t.Helper()
+	metrics.MustInit("test")
+
+	store := &fakeCodeStore{}
+	p, err := NewPool(&countingGenerator{}, store, PoolConfig{
+		Domain:          "sho.rt",
+		Size:            size,
+		RefillThreshold: threshold,
+		ReservationTTL:  10 * time.Minute,
+	}, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, store
+}
+
+func mustTake(t *testing.T, p *Pool, n int) []string {
+	t.Helper()
+	codes := make([]string, 0, n)
+	for range n {
+		code, err := p.GenerateShortCode()
+		if err != nil {
+			t.Fatalf("GenerateShortCode() error = %v", err)
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func refillRequested(p *Pool) bool {
+	select {
+	case <-p.refill:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPoolRefillsAtThreshold(t *testing.T) {
+	p, store := newTestPool(t, 10, 3)
+	ctx := context.Background()
+
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+
+	mustTake(t, p, 7)
+	if refillRequested(p) {
+		t.Fatal("refill requested with 3 codes left, threshold is 3")
+	}
+	mustTake(t, p, 1)
+	if !refillRequested(p) {
+		t.Fatal("refill not requested with 2 codes left")
+	}
+
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+	if got := len(p.codes); got != 10 {
+		t.Errorf("pool holds %d codes after refill, want 10", got)
+	}
+	if reserved, _ := store.snapshot(); len(reserved) != 18 {
+		t.Errorf("reserved %d codes, want 18", len(reserved))
+	}
+}
+
+func TestPoolRefillsInlineWhenEmpty(t *testing.T) {
+	p, store := newTestPool(t, 5, 1)
+
+	codes := mustTake(t, p, 1)
+	if codes[0] != "c0" {
+		t.Errorf("first code = %q, want c0", codes[0])
+	}
+	if reserved, _ := store.snapshot(); len(reserved) != 5 {
+		t.Errorf("reserved %d codes, want 5", len(reserved))
+	}
+}
+
+func TestPoolHandsOutCodesDuringRefill(t *testing.T) {
+	p, store := newTestPool(t, 10, 5)
+	ctx := context.Background()
+
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+	handed := mustTake(t, p, 6)
+
+	store.block()
+	filled := make(chan error, 1)
+	go func() { filled <- p.fill(ctx) }()
+	<-store.entered
+
+	// The refill is stuck in the store; the buffered codes must still be handed out.
+	taken := make(chan string, 4)
+	go func() {
+		for range 4 {
+			code, _ := p.GenerateShortCode()
+			taken <- code
+		}
+	}()
+	for range 4 {
+		select {
+		case code := <-taken:
+			if code == "" {
+				t.Fatal("GenerateShortCode failed during a running refill")
+			}
+			handed = append(handed, code)
+		case <-time.After(time.Second):
+			t.Fatal("GenerateShortCode blocked behind a running refill")
+		}
+	}
+
+	store.unblock()
+	if err := <-filled; err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+	handed = append(handed, mustTake(t, p, 6)...)
+
+	seen := make(map[string]bool, len(handed))
+	for _, code := range handed {
+		if seen[code] {
+			t.Fatalf("code %q handed out twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestPoolRecyclesAgedCodes(t *testing.T) {
+	p, store := newTestPool(t, 4, 0)
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+	mustTake(t, p, 2) // c0, c1; c2 and c3 stay buffered
+
+	now = now.Add(4 * time.Minute)
+	if err := p.fill(ctx); err != nil { // c4 and c5
+		t.Fatalf("fill() error = %v", err)
+	}
+
+	// Half a TTL after the first fill only its codes are released.
+	now = now.Add(2 * time.Minute)
+	p.recycle(ctx)
+
+	if _, released := store.snapshot(); !slices.Equal(released, []string{"c2", "c3"}) {
+		t.Errorf("released %v, want [c2 c3]", released)
+	}
+	if got := codeStrings(p.codes); !slices.Equal(got, []string{"c4", "c5"}) {
+		t.Errorf("pool holds %v, want [c4 c5]", got)
+	}
+	want := now.Add(-10 * time.Minute)
+	if len(store.deleteBefore) != 1 || !store.deleteBefore[0].Equal(want) {
+		t.Errorf("deleted reservations before %v, want [%v]", store.deleteBefore, want)
+	}
+}
+
+func TestPoolCloseReleasesCodes(t *testing.T) {
+	p, store := newTestPool(t, 5, 1)
+	ctx := context.Background()
+
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() error = %v", err)
+	}
+	mustTake(t, p, 2)
+
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, released := store.snapshot(); !slices.Equal(released, []string{"c2", "c3", "c4"}) {
+		t.Errorf("released %v, want [c2 c3 c4]", released)
+	}
+
+	// A closed pool no longer reserves codes and falls back to the generator.
+	if err := p.fill(ctx); err != nil {
+		t.Fatalf("fill() after Close error = %v", err)
+	}
+	if code := mustTake(t, p, 1)[0]; code != "c5" {
+		t.Errorf("code after Close = %q, want c5", code)
+	}
+	if reserved, _ := store.snapshot(); len(reserved) != 5 {
+		t.Errorf("reserved %d codes, want 5", len(reserved))
+	}
+}
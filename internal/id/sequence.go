@@ -0,0 +1,115 @@
+package id
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MinSequenceKeyLength is the shortest permutation key accepted by NewSequenceGenerator.
+const MinSequenceKeyLength = 16
+
+// maxCodeLength keeps 62^length within uint64.
+const maxCodeLength = 10
+
+// reserveTimeout bounds a single reservation of IDs from the sequence.
+const reserveTimeout = 5 * time.Second
+
+// ErrSequenceExhausted is returned when the sequence has passed the number of codes of the configured length.
+var ErrSequenceExhausted = errors.New("id sequence exhausted for code length")
+
+// Sequence hands out unique IDs that are never reused, such as values of a Postgres sequence.
+type Sequence interface {
+	// ReserveIDs reserves n IDs for the caller.
+	ReserveIDs(ctx context.Context, n int) ([]int64, error)
+}
+
+// SequenceGenerator builds short codes from sequence IDs. Each ID is passed through a keyed
+// permutation and written as a fixed-length base62 number, so codes never collide with each
+// other and consecutive IDs do not produce guessable neighbouring codes.
+type SequenceGenerator struct {
+	seq       Sequence
+	perm      *permutation
+	length    int
+	blockSize int
+
+	mu  sync.Mutex
+	ids []int64
+}
+
+// NewSequenceGenerator creates a generator producing codes of the given length.
+// IDs are reserved from seq blockSize at a time and kept in memory until used.
+func NewSequenceGenerator(seq Sequence, key []byte, length, blockSize int) (*SequenceGenerator, error) {
+	if length < 1 || length > maxCodeLength {
+		return nil, fmt.Errorf("code length must be in 1..%d, got %d", maxCodeLength, length)
+	}
+	if len(key) < MinSequenceKeyLength {
+		return nil, fmt.Errorf("permutation key must be at least %d bytes", MinSequenceKeyLength)
+	}
+	if blockSize < 1 {
+		blockSize = 1
+	}
+
+	space := uint64(1)
+	for range length {
+		space *= uint64(len(alphabet))
+	}
+	perm, err := newPermutation(key, space)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SequenceGenerator{
+		seq:       seq,
+		perm:      perm,
+		length:    length,
+		blockSize: blockSize,
+	}, nil
+}
+
+// GenerateShortCode takes the next reserved ID and encodes it as a short code.
+func (g *SequenceGenerator) GenerateShortCode() (string, error) {
+	id, err := g.nextID()
+	if err != nil {
+		return "", err
+	}
+	if id < 0 || uint64(id) >= g.perm.n {
+		return "", fmt.Errorf("%w: id %d", ErrSequenceExhausted, id)
+	}
+	return encodeBase62(g.perm.apply(uint64(id)), g.length), nil
+}
+
+func (g *SequenceGenerator) nextID() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.ids) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), reserveTimeout)
+		defer cancel()
+
+		ids, err := g.seq.ReserveIDs(ctx, g.blockSize)
+		if err != nil {
+			return 0, fmt.Errorf("reserve ids: %w", err)
+		}
+		if len(ids) == 0 {
+			return 0, errors.New("reserve ids: sequence returned no ids")
+		}
+		g.ids = ids
+	}
+
+	id := g.ids[0]
+	g.ids = g.ids[1:]
+	return id, nil
+}
+
+// encodeBase62 writes v as a base62 number of exactly length digits, padding with the zero digit.
+func encodeBase62(v uint64, length int) string {
+	b := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		b[i] = alphabet[v%uint64(len(alphabet))]
+		v /= uint64(len(alphabet))
+	}
+	return string(b)
+}
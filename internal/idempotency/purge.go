@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+// purgeBatchSize is how many expired keys one purge statement deletes.
+const purgeBatchSize = 1000
+
+// Purger deletes expired idempotency keys.
+type Purger interface {
+	// PurgeExpired deletes up to limit expired keys and returns how many were deleted.
+	PurgeExpired(ctx context.Context, limit int) (int64, error)
+}
+
+// RunPurger deletes expired keys every interval until ctx is canceled. Each run deletes
+// in batches until no expired keys are left.
+func RunPurger(ctx context.Context, p Purger, interval time.Duration, log logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var total int64
+		for ctx.Err() == nil {
+			n, err := p.PurgeExpired(ctx, purgeBatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warn("failed to purge expired idempotency keys", logger.Error(err))
+				}
+				break
+			}
+			total += n
+			if n < purgeBatchSize {
+				break
+			}
+		}
+		if total > 0 {
+			log.Info("purged expired idempotency keys", logger.Int64("count", total))
+		}
+	}
+}
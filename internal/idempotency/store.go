@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// HeaderName is the request header carrying the client-chosen idempotency key.
+const HeaderName = "Idempotency-Key"
+
+// Record is a stored idempotency key. A record without a StatusCode is still being processed.
+type Record struct {
+	RequestHash string
+	StatusCode  int
+	Response    []byte
+}
+
+// Completed reports whether the original request has finished and its response can be replayed.
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store persists idempotency keys per owner.
+type Store interface {
+	// Reserve claims key for a new request. If the key is already taken and not expired,
+	// it returns the existing record and reserved=false.
+	Reserve(ctx context.Context, ownerID int64, key, requestHash string, expiresAt time.Time) (existing *Record, reserved bool, err error)
+	// Complete stores the response for a previously reserved key.
+	Complete(ctx context.Context, ownerID int64, key string, statusCode int, response []byte) error
+	// Release drops a reservation so the client can retry with the same key.
+	Release(ctx context.Context, ownerID int64, key string) error
+}
+
+// HashRequest returns a stable fingerprint of a request body used to detect key reuse with a different payload.
+func HashRequest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
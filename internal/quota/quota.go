@@ -0,0 +1,257 @@
+// Package quota enforces per-owner plan limits on link creation and reports usage.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+var (
+	// ErrLimitExceeded is wrapped by every *LimitError.
+	ErrLimitExceeded = errors.New("quota exceeded")
+	// ErrInvalidPlans is returned when plan definitions cannot be parsed.
+	ErrInvalidPlans = errors.New("invalid quota plans")
+)
+
+// Limit codes reported in LimitError.Code.
+const (
+	CodeMonthlyLinks = "monthly_links_exceeded"
+	CodeBatchSize    = "batch_size_exceeded"
+)
+
+// unlimitedPlan is used as the default when no plans are configured.
+const unlimitedPlan = "unlimited"
+
+// LimitError describes which plan limit a request hit.
+type LimitError struct {
+	Code  string
+	Plan  string
+	Limit int64
+	// Used is the consumption counted against Limit before the request.
+	Used int64
+	// Requested is the amount the rejected request asked for.
+	Requested int64
+	// ResetAt is when the limit resets; zero for per-request limits.
+	ResetAt time.Time
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	switch e.Code {
+	case CodeBatchSize:
+		return fmt.Sprintf("%s: plan %q allows at most %d items per batch, got %d", ErrLimitExceeded, e.Plan, e.Limit, e.Requested)
+	default:
+		return fmt.Sprintf("%s: plan %q allows %d links per month, %d already used", ErrLimitExceeded, e.Plan, e.Limit, e.Used)
+	}
+}
+
+// Unwrap lets errors.Is match ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Plan defines the limits applied to an owner. Zero limits mean unlimited.
+type Plan struct {
+	Name         string
+	MonthlyLinks int64
+	MaxBatchSize int
+}
+
+// ParsePlans parses comma-separated "name:monthly_links:max_batch_size" definitions.
+func ParsePlans(s string) (map[string]Plan, error) {
+	plans := make(map[string]Plan)
+	for _, def := range strings.Split(s, ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+
+		parts := strings.Split(def, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("%w: %q must look like name:monthly_links:max_batch_size", ErrInvalidPlans, def)
+		}
+		monthly, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || monthly < 0 {
+			return nil, fmt.Errorf("%w: bad monthly links limit in %q", ErrInvalidPlans, def)
+		}
+		batch, err := strconv.Atoi(parts[2])
+		if err != nil || batch < 0 {
+			return nil, fmt.Errorf("%w: bad batch size limit in %q", ErrInvalidPlans, def)
+		}
+		plans[parts[0]] = Plan{Name: parts[0], MonthlyLinks: monthly, MaxBatchSize: batch}
+	}
+	return plans, nil
+}
+
+// Usage is an owner's consumption in the current billing period.
+type Usage struct {
+	Plan         Plan
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	LinksCreated int64
+}
+
+// Repository persists plan assignments and usage counters.
+type Repository interface {
+	// GetOwnerPlan returns the plan name assigned to the owner, or "" when none is assigned.
+	GetOwnerPlan(ctx context.Context, ownerID int64) (string, error)
+	// AddLinks adds n to the owner's counter for the period unless the total would exceed limit
+	// (zero means unlimited). It returns the counter value and whether it was incremented.
+	AddLinks(ctx context.Context, ownerID int64, period time.Time, n, limit int64) (int64, bool, error)
+	// SubtractLinks refunds n links, never going below zero.
+	SubtractLinks(ctx context.Context, ownerID int64, period time.Time, n int64) error
+	GetLinksCreated(ctx context.Context, ownerID int64, period time.Time) (int64, error)
+}
+
+// Service enforces plan limits and reports usage.
+type Service interface {
+	// CheckBatchSize returns a *LimitError if the owner's plan does not allow n items per batch.
+	CheckBatchSize(ctx context.Context, ownerID int64, n int) error
+	// ReserveLinks counts n new links against the monthly limit, or returns a *LimitError.
+	ReserveLinks(ctx context.Context, ownerID int64, n int) error
+	// ReleaseLinks refunds links that were reserved but not created.
+	ReleaseLinks(ctx context.Context, ownerID int64, n int) error
+	GetUsage(ctx context.Context, ownerID int64) (*Usage, error)
+}
+
+type service struct {
+	repo        Repository
+	plans       map[string]Plan
+	defaultPlan string
+	logger      logger.Logger
+}
+
+// NewService constructs a quota Service. Owners without an assigned plan get defaultPlan;
+// when plans is empty every owner is unlimited and usage is only metered.
+func NewService(repo Repository, plans map[string]Plan, defaultPlan string, logger logger.Logger) (Service, error) {
+	if len(plans) == 0 {
+		plans = map[string]Plan{unlimitedPlan: {Name: unlimitedPlan}}
+		defaultPlan = unlimitedPlan
+	}
+	if _, ok := plans[defaultPlan]; !ok {
+		return nil, fmt.Errorf("%w: default plan %q is not defined", ErrInvalidPlans, defaultPlan)
+	}
+
+	return &service{
+		repo:        repo,
+		plans:       plans,
+		defaultPlan: defaultPlan,
+		logger:      logger,
+	}, nil
+}
+
+// CheckBatchSize rejects batches larger than the owner's plan allows.
+func (s *service) CheckBatchSize(ctx context.Context, ownerID int64, n int) error {
+	plan, err := s.ownerPlan(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if plan.MaxBatchSize > 0 && n > plan.MaxBatchSize {
+		return &LimitError{
+			Code:      CodeBatchSize,
+			Plan:      plan.Name,
+			Limit:     int64(plan.MaxBatchSize),
+			Requested: int64(n),
+		}
+	}
+	return nil
+}
+
+// ReserveLinks atomically counts n links against the owner's monthly limit.
+func (s *service) ReserveLinks(ctx context.Context, ownerID int64, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	plan, err := s.ownerPlan(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	start, end := period(time.Now())
+	used, ok, err := s.repo.AddLinks(ctx, ownerID, start, int64(n), plan.MonthlyLinks)
+	if err != nil {
+		return fmt.Errorf("add links usage: %w", err)
+	}
+	if !ok {
+		s.logger.Info("monthly link quota exceeded",
+			logger.Int64("owner_id", ownerID),
+			logger.String("plan", plan.Name),
+			logger.Int64("used", used),
+		)
+		return &LimitError{
+			Code:      CodeMonthlyLinks,
+			Plan:      plan.Name,
+			Limit:     plan.MonthlyLinks,
+			Used:      used,
+			Requested: int64(n),
+			ResetAt:   end,
+		}
+	}
+	return nil
+}
+
+// ReleaseLinks refunds n links to the current period.
+func (s *service) ReleaseLinks(ctx context.Context, ownerID int64, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	start, _ := period(time.Now())
+	if err := s.repo.SubtractLinks(ctx, ownerID, start, int64(n)); err != nil {
+		return fmt.Errorf("subtract links usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsage returns the owner's plan and consumption in the current period.
+func (s *service) GetUsage(ctx context.Context, ownerID int64) (*Usage, error) {
+	plan, err := s.ownerPlan(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := period(time.Now())
+	created, err := s.repo.GetLinksCreated(ctx, ownerID, start)
+	if err != nil {
+		return nil, fmt.Errorf("get links usage: %w", err)
+	}
+
+	return &Usage{
+		Plan:         plan,
+		PeriodStart:  start,
+		PeriodEnd:    end,
+		LinksCreated: created,
+	}, nil
+}
+
+// ownerPlan resolves the owner's plan, falling back to the default for unknown or unassigned plans.
+func (s *service) ownerPlan(ctx context.Context, ownerID int64) (Plan, error) {
+	name, err := s.repo.GetOwnerPlan(ctx, ownerID)
+	if err != nil {
+		return Plan{}, fmt.Errorf("get owner plan: %w", err)
+	}
+	if plan, ok := s.plans[name]; ok {
+		return plan, nil
+	}
+	if name != "" {
+		s.logger.Warn("owner has unknown plan, using default",
+			logger.Int64("owner_id", ownerID),
+			logger.String("plan", name),
+		)
+	}
+	return s.plans[s.defaultPlan], nil
+}
+
+// period returns the calendar month (UTC) containing t as [start, end).
+func period(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
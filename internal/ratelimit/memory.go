@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneEvery controls how often the memory limiter drops buckets that are full again.
+const pruneEvery = time.Minute
+
+// Memory is an in-process Limiter. Limits are enforced per process only.
+type Memory struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewMemory constructs an in-memory Limiter.
+func NewMemory() *Memory {
+	return &Memory{
+		tats: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Allow consumes one request from the bucket identified by key.
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.prune(now)
+
+	res, tat := gcra(now, m.tats[key], limit)
+	if res.Allowed {
+		m.tats[key] = tat
+	}
+	return res, nil
+}
+
+// prune drops buckets whose theoretical arrival time has passed, i.e. that are full again.
+func (m *Memory) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < pruneEvery {
+		return
+	}
+	m.lastPrune = now
+
+	for key, tat := range m.tats {
+		if !tat.After(now) {
+			delete(m.tats, key)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package ratelimit provides GCRA (token bucket) request rate limiting.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+// ErrInvalidLimit is returned when a limit definition cannot be parsed.
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit allows Requests per Window, with bursts of up to Requests. The zero Limit disables limiting.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+// interval is the time it takes to earn back one request.
+func (l Limit) interval() time.Duration {
+	return l.Window / time.Duration(l.Requests)
+}
+
+// String formats the limit in the form accepted by ParseLimit.
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// ParseLimit parses "requests/window", e.g. "100/1m". An empty string or "0" disables the limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+
+	reqStr, winStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%w: %q must look like requests/window", ErrInvalidLimit, s)
+	}
+	requests, err := strconv.Atoi(reqStr)
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("%w: bad request count in %q", ErrInvalidLimit, s)
+	}
+	window, err := time.ParseDuration(winStr)
+	if err != nil || window <= 0 {
+		return Limit{}, fmt.Errorf("%w: bad window in %q", ErrInvalidLimit, s)
+	}
+	// Limiters count time in microseconds; a shorter emission interval would round to zero.
+	if int64(requests) > window.Microseconds() {
+		return Limit{}, fmt.Errorf("%w: %q allows more than one request per microsecond", ErrInvalidLimit, s)
+	}
+	return Limit{Requests: requests, Window: window}, nil
+}
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+	// RetryAfter is how long until the next request is allowed; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter consumes one request from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// gcra applies the generic cell rate algorithm to the bucket's theoretical arrival time tat
+// and returns the result together with the new tat to store (zero when the request is denied).
+func gcra(now, tat time.Time, limit Limit) (Result, time.Time) {
+	interval := limit.interval()
+	tolerance := limit.Window
+
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-tolerance)
+
+	if now.Before(allowAt) {
+		return Result{
+			Allowed:    false,
+			Limit:      limit.Requests,
+			Remaining:  0,
+			ResetAfter: tat.Sub(now),
+			RetryAfter: allowAt.Sub(now),
+		}, time.Time{}
+	}
+
+	return Result{
+		Allowed:    true,
+		Limit:      limit.Requests,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, newTAT
+}
+
+// fallbackProbeInterval is how often a degraded Fallback retries its primary limiter.
+const fallbackProbeInterval = 5 * time.Second
+
+// Fallback uses the primary limiter and switches to the secondary one while the primary fails,
+// e.g. an in-memory limiter when Redis is down. While degraded it does not wait on the primary:
+// requests go straight to the secondary and one request per probe interval tries the primary.
+type Fallback struct {
+	primary       Limiter
+	secondary     Limiter
+	logger        logger.Logger
+	probeInterval time.Duration
+	now           func() time.Time
+	// degraded is set while the primary limiter is failing.
+	degraded atomic.Bool
+	// nextProbe is the Unix time in nanoseconds after which a degraded Fallback tries the primary again.
+	nextProbe atomic.Int64
+}
+
+// NewFallback constructs a Limiter that falls back to secondary on primary errors.
+func NewFallback(primary, secondary Limiter, logger logger.Logger) *Fallback {
+	return &Fallback{
+		primary:       primary,
+		secondary:     secondary,
+		logger:        logger,
+		probeInterval: fallbackProbeInterval,
+		now:           time.Now,
+	}
+}
+
+// Allow checks the primary limiter and falls back to the secondary one on error or while degraded.
+func (f *Fallback) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if f.degraded.Load() && !f.claimProbe() {
+		return f.secondary.Allow(ctx, key, limit)
+	}
+
+	res, err := f.primary.Allow(ctx, key, limit)
+	if err == nil {
+		if f.degraded.Swap(false) {
+			f.logger.Info("rate limiter recovered")
+		}
+		return res, nil
+	}
+
+	f.nextProbe.Store(f.now().Add(f.probeInterval).UnixNano())
+	if !f.degraded.Swap(true) {
+		f.logger.Warn("rate limiter unavailable, using fallback", logger.Error(err))
+	}
+	return f.secondary.Allow(ctx, key, limit)
+}
+
+// claimProbe reports whether the caller may probe the primary limiter now.
+// At most one caller wins per probe interval.
+func (f *Fallback) claimProbe() bool {
+	next := f.nextProbe.Load()
+	now := f.now()
+	if now.UnixNano() < next {
+		return false
+	}
+	return f.nextProbe.CompareAndSwap(next, now.Add(f.probeInterval).UnixNano())
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...logger.Field) {}
+func (nopLogger) Info(string, ...logger.Field)  {}
+func (nopLogger) Warn(string, ...logger.Field)  {}
+func (nopLogger) Error(string, ...logger.Field) {}
+func (nopLogger) Fatal(string, ...logger.Field) {}
+
+// countingLimiter counts calls and fails while err is set.
+type countingLimiter struct {
+	calls int
+	err   error
+}
+
+func (c *countingLimiter) Allow(context.Context, string, Limit) (Result, error) {
+	c.calls++
+	if c.err != nil {
+		return Result{}, c.err
+	}
+	return Result{Allowed: true, Limit: 1, Remaining: 1}, nil
+}
+
+func TestFallbackSkipsPrimaryWhileDegraded(t *testing.T) {
+	primary := &countingLimiter{err: errors.New("redis down")}
+	secondary := &countingLimiter{}
+	now := time.Unix(1_700_000_000, 0)
+
+	f := NewFallback(primary, secondary, nopLogger{})
+	f.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Window: time.Minute}
+
+	allow := func() {
+		t.Helper()
+		if _, err := f.Allow(context.Background(), "k", limit); err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+	}
+
+	allow()
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Fatalf("after failure: primary %d, secondary %d calls; want 1, 1", primary.calls, secondary.calls)
+	}
+
+	for range 100 {
+		allow()
+	}
+	if primary.calls != 1 {
+		t.Fatalf("primary called %d times while degraded, want 1", primary.calls)
+	}
+	if secondary.calls != 101 {
+		t.Fatalf("secondary called %d times, want 101", secondary.calls)
+	}
+
+	// After the probe interval exactly one request probes the still failing primary.
+	now = now.Add(fallbackProbeInterval)
+	allow()
+	allow()
+	if primary.calls != 2 {
+		t.Fatalf("primary called %d times after one probe interval, want 2", primary.calls)
+	}
+
+	// A successful probe restores the primary.
+	primary.err = nil
+	now = now.Add(fallbackProbeInterval)
+	allow()
+	allow()
+	if primary.calls != 4 {
+		t.Fatalf("primary called %d times after recovery, want 4", primary.calls)
+	}
+	if f.degraded.Load() {
+		t.Fatal("fallback still degraded after a successful probe")
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "100/1m", want: Limit{Requests: 100, Window: time.Minute}},
+		{in: "1000000/1s", want: Limit{Requests: 1000000, Window: time.Second}},
+		{in: "1000001/1s", wantErr: true},
+		{in: "5/1ns", wantErr: true},
+		{in: "100", wantErr: true},
+		{in: "x/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidLimit) {
+					t.Errorf("ParseLimit(%q) error = %v, want %v", tt.in, err, ErrInvalidLimit)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseLimit(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
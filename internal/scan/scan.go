@@ -0,0 +1,150 @@
+// Package scan detects clients that enumerate short codes by counting their not-found lookups.
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// storeRetryInterval is how long a Guard skips a failing store before trying it again,
+// so that redirects do not wait on store timeouts during an outage.
+const storeRetryInterval = 5 * time.Second
+
+// Mode selects what happens to requests from a flagged client.
+type Mode string
+
+const (
+	// ModeBlock rejects requests until the block expires.
+	ModeBlock Mode = "block"
+	// ModeTarpit delays requests until the block expires but still serves them.
+	ModeTarpit Mode = "tarpit"
+)
+
+// Config controls scan detection. A zero Threshold disables it.
+type Config struct {
+	// Threshold is the number of not-found lookups per Window that flags a client.
+	Threshold int
+	Window    time.Duration
+	// BlockFor is how long a flagged client stays blocked or tarpitted.
+	BlockFor time.Duration
+	Mode     Mode
+	// TarpitDelay is the delay added to each request in ModeTarpit.
+	TarpitDelay time.Duration
+}
+
+// Store keeps per-client miss counters and blocks.
+type Store interface {
+	// RecordMiss counts a not-found lookup for ip within window and blocks ip for blockFor once
+	// threshold is reached. It reports whether this call created the block.
+	RecordMiss(ctx context.Context, ip string, threshold int, window, blockFor time.Duration) (bool, error)
+	// BlockedFor returns the remaining block time for ip, or zero when it is not blocked.
+	BlockedFor(ctx context.Context, ip string) (time.Duration, error)
+}
+
+// Decision tells the caller how to treat a request.
+type Decision struct {
+	// Blocked is set when the request must be rejected; RetryAfter says for how long.
+	Blocked    bool
+	RetryAfter time.Duration
+	// Delay is how long to stall the request before serving it.
+	Delay time.Duration
+}
+
+// Guard applies the scan detection policy on top of a Store. While the store fails, detection
+// is skipped and one call per retry interval probes the store.
+type Guard struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+	// retryAt is the Unix time in nanoseconds before which the failing store is skipped; zero while it works.
+	retryAt atomic.Int64
+}
+
+// NewGuard constructs a Guard. It returns nil when detection is disabled by cfg.
+func NewGuard(store Store, cfg Config) (*Guard, error) {
+	if cfg.Threshold <= 0 {
+		return nil, nil
+	}
+	if cfg.Window <= 0 || cfg.BlockFor <= 0 {
+		return nil, fmt.Errorf("scan detection window and block duration must be positive")
+	}
+	switch cfg.Mode {
+	case ModeBlock, ModeTarpit:
+	default:
+		return nil, fmt.Errorf("unknown scan detection mode %q", cfg.Mode)
+	}
+
+	return &Guard{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+	}, nil
+}
+
+// Mode returns the configured mode.
+func (g *Guard) Mode() Mode {
+	return g.cfg.Mode
+}
+
+// Admit decides how to treat a request from ip.
+func (g *Guard) Admit(ctx context.Context, ip string) (Decision, error) {
+	if !g.storeAvailable() {
+		return Decision{}, nil
+	}
+	remaining, err := g.store.BlockedFor(ctx, ip)
+	g.storeDone(err)
+	if err != nil {
+		return Decision{}, fmt.Errorf("get scan block: %w", err)
+	}
+	if remaining <= 0 {
+		return Decision{}, nil
+	}
+
+	if g.cfg.Mode == ModeTarpit {
+		return Decision{Delay: g.cfg.TarpitDelay}, nil
+	}
+	return Decision{Blocked: true, RetryAfter: remaining}, nil
+}
+
+// RecordMiss counts a not-found lookup from ip and reports whether ip just got flagged.
+func (g *Guard) RecordMiss(ctx context.Context, ip string) (bool, error) {
+	if !g.storeAvailable() {
+		return false, nil
+	}
+	flagged, err := g.store.RecordMiss(ctx, ip, g.cfg.Threshold, g.cfg.Window, g.cfg.BlockFor)
+	g.storeDone(err)
+	if err != nil {
+		return false, fmt.Errorf("record scan miss: %w", err)
+	}
+	return flagged, nil
+}
+
+// storeAvailable reports whether the store may be called. While it is failing, only one
+// caller per retry interval gets through as a probe.
+func (g *Guard) storeAvailable() bool {
+	retryAt := g.retryAt.Load()
+	if retryAt == 0 {
+		return true
+	}
+	now := g.now()
+	if now.UnixNano() < retryAt {
+		return false
+	}
+	return g.retryAt.CompareAndSwap(retryAt, now.Add(storeRetryInterval).UnixNano())
+}
+
+// storeDone records the outcome of a store call.
+func (g *Guard) storeDone(err error) {
+	if err == nil {
+		g.retryAt.Store(0)
+		return
+	}
+	// A client that went away says nothing about the store.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	g.retryAt.Store(g.now().Add(storeRetryInterval).UnixNano())
+}
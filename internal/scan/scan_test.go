@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingStore counts calls and fails while err is set.
+type failingStore struct {
+	calls int
+	err   error
+}
+
+func (s *failingStore) RecordMiss(context.Context, string, int, time.Duration, time.Duration) (bool, error) {
+	s.calls++
+	return false, s.err
+}
+
+func (s *failingStore) BlockedFor(context.Context, string) (time.Duration, error) {
+	s.calls++
+	return 0, s.err
+}
+
+func TestGuardSkipsFailingStore(t *testing.T) {
+	store := &failingStore{err: errors.New("redis down")}
+	g, err := NewGuard(store, Config{Threshold: 3, Window: time.Minute, BlockFor: time.Minute, Mode: ModeBlock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	g.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := g.Admit(ctx, "1.2.3.4"); err == nil {
+		t.Fatal("Admit() error = nil, want store error")
+	}
+	for range 50 {
+		if _, err := g.Admit(ctx, "1.2.3.4"); err != nil {
+			t.Fatalf("Admit() while skipping store error = %v", err)
+		}
+		if _, err := g.RecordMiss(ctx, "1.2.3.4"); err != nil {
+			t.Fatalf("RecordMiss() while skipping store error = %v", err)
+		}
+	}
+	if store.calls != 1 {
+		t.Fatalf("store called %d times while failing, want 1", store.calls)
+	}
+
+	store.err = nil
+	now = now.Add(storeRetryInterval)
+	if _, err := g.Admit(ctx, "1.2.3.4"); err != nil {
+		t.Fatalf("Admit() probe error = %v", err)
+	}
+	if _, err := g.RecordMiss(ctx, "1.2.3.4"); err != nil {
+		t.Fatalf("RecordMiss() after recovery error = %v", err)
+	}
+	if store.calls != 3 {
+		t.Fatalf("store called %d times after recovery, want 3", store.calls)
+	}
+}
@@ -0,0 +1,89 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+)
+
+// ErrForbidden is returned when the user can see a link but their role does not allow the action.
+var ErrForbidden = errors.New("forbidden")
+
+// WorkspaceAuthorizer checks a user's permissions within a workspace.
+type WorkspaceAuthorizer interface {
+	Authorize(ctx context.Context, userID, workspaceID int64, action workspace.Action) error
+}
+
+// CheckLinkAccess reports whether userID may view the link and its statistics.
+func (s *service) CheckLinkAccess(ctx context.Context, userID, linkID int64) error {
+	_, err := s.getAuthorizedLink(ctx, userID, linkID, workspace.ActionView)
+	return err
+}
+
+// TransferLink moves a link into another workspace. The user needs admin rights over the
+// link's current workspace (or to own it, for personal links) and edit rights in the target.
+func (s *service) TransferLink(ctx context.Context, userID, linkID, workspaceID int64) (*Link, error) {
+	link, err := s.getAuthorizedLink(ctx, userID, linkID, workspace.ActionAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeWorkspace(ctx, userID, &workspaceID, workspace.ActionEdit); err != nil {
+		return nil, err
+	}
+
+	link.WorkspaceID = &workspaceID
+	if err := s.cfg.LinksRepo.UpdateLink(ctx, link); err != nil {
+		return nil, fmt.Errorf("update link: %w", err)
+	}
+
+	s.invalidateCache(ctx, link)
+	return link, nil
+}
+
+// getAuthorizedLink loads a link and checks that userID may perform action on it.
+func (s *service) getAuthorizedLink(ctx context.Context, userID, linkID int64, action workspace.Action) (*Link, error) {
+	link, err := s.cfg.LinksRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("get link by id: %w", err)
+	}
+	if err := s.authorizeLink(ctx, userID, link, action); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// authorizeLink checks access to a link: personal links are available to their owner only,
+// workspace links according to the user's role.
+func (s *service) authorizeLink(ctx context.Context, userID int64, link *Link, action workspace.Action) error {
+	if link.WorkspaceID == nil {
+		if link.OwnerID != userID {
+			return ErrNotFound
+		}
+		return nil
+	}
+	return s.authorizeWorkspace(ctx, userID, link.WorkspaceID, action)
+}
+
+// authorizeWorkspace checks the user's role in workspaceID. A nil workspace means the
+// user's personal space, which is always accessible.
+func (s *service) authorizeWorkspace(ctx context.Context, userID int64, workspaceID *int64, action workspace.Action) error {
+	if workspaceID == nil {
+		return nil
+	}
+	if s.cfg.Workspaces == nil {
+		return ErrNotFound
+	}
+
+	err := s.cfg.Workspaces.Authorize(ctx, userID, *workspaceID, action)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, workspace.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, workspace.ErrForbidden):
+		return ErrForbidden
+	}
+	return fmt.Errorf("authorize workspace: %w", err)
+}
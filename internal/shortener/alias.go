@@ -0,0 +1,62 @@
+package shortener
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	minAliasLength = 3
+	maxAliasLength = 32
+)
+
+// reservedAliases lists short codes that collide with API routes or are kept for internal use.
+var reservedAliases = map[string]struct{}{
+	"api":     {},
+	"admin":   {},
+	"healthz": {},
+	"readyz":  {},
+	"metrics": {},
+	"static":  {},
+	"assets":  {},
+	"login":   {},
+	"logout":  {},
+	"docs":    {},
+}
+
+// validateAlias checks a custom alias against the charset, length and reserved-word policy.
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: length must be between %d and %d", ErrInvalidAlias, minAliasLength, maxAliasLength)
+	}
+
+	for _, c := range alias {
+		if !isAliasChar(c) {
+			return fmt.Errorf("%w: unsupported character %q", ErrInvalidAlias, c)
+		}
+	}
+
+	if alias[0] == '-' || alias[0] == '_' {
+		return fmt.Errorf("%w: must start with a letter or digit", ErrInvalidAlias)
+	}
+
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+// isAliasChar reports whether c is allowed in a custom alias.
+func isAliasChar(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return true
+	case c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return true
+	case c == '-' || c == '_':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package shortener
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias   string
+		wantErr bool
+	}{
+		{alias: "spring-sale"},
+		{alias: "Promo_2025"},
+		{alias: "abc"},
+		{alias: "ab", wantErr: true},
+		{alias: "this-alias-is-far-too-long-to-be-ok", wantErr: true},
+		{alias: "-leading", wantErr: true},
+		{alias: "_leading", wantErr: true},
+		{alias: "with space", wantErr: true},
+		{alias: "slash/path", wantErr: true},
+		{alias: "api", wantErr: true},
+		{alias: "healthz", wantErr: true},
+		{alias: "readyz", wantErr: true},
+		{alias: "ReadyZ", wantErr: true},
+		{alias: "metrics", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			err := validateAlias(tt.alias)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAlias) {
+					t.Errorf("validateAlias(%q) error = %v, want %v", tt.alias, err, ErrInvalidAlias)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateAlias(%q) error = %v, want nil", tt.alias, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+)
+
+// MaxBatchSize caps the number of links created by a single CreateShortLinks call.
+const MaxBatchSize = 1000
+
+// ErrBatchTooLarge is returned when a batch is empty or exceeds MaxBatchSize.
+var ErrBatchTooLarge = errors.New("batch size out of range")
+
+// BatchResult holds the outcome of one item of a bulk creation. Exactly one of Link and Err is set.
+type BatchResult struct {
+	Link *Link
+	Err  error
+}
+
+// CreateShortLinks creates many links at once. Invalid items and conflicting aliases are
+// reported per item and do not fail the rest of the batch.
+func (s *service) CreateShortLinks(ctx context.Context, ownerID int64, items []CreateLinkParams) ([]BatchResult, error) {
+	if len(items) == 0 || len(items) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: got %d items, allowed 1..%d", ErrBatchTooLarge, len(items), MaxBatchSize)
+	}
+	if err := s.checkBatchQuota(ctx, ownerID, len(items)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	results := make([]BatchResult, len(items))
+
+	pending := make([]*Link, 0, len(items))
+	pendingIdx := make([]int, 0, len(items))
+	workspaceAccess := make(map[int64]error)
+
+	for i, item := range items {
+		link, err := s.prepareLink(ownerID, item, now)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if item.WorkspaceID != nil {
+			accessErr, ok := workspaceAccess[*item.WorkspaceID]
+			if !ok {
+				accessErr = s.authorizeWorkspace(ctx, ownerID, item.WorkspaceID, workspace.ActionEdit)
+				workspaceAccess[*item.WorkspaceID] = accessErr
+			}
+			if accessErr != nil {
+				results[i].Err = accessErr
+				continue
+			}
+		}
+		if link.ShortCode == "" {
+			if link.ShortCode, err = s.cfg.IDGen.GenerateShortCode(); err != nil {
+				results[i].Err = fmt.Errorf("generate short code: %w", err)
+				continue
+			}
+		}
+		pending = append(pending, link)
+		pendingIdx = append(pendingIdx, i)
+	}
+
+	// The whole batch is reserved at once; a batch that does not fit the remaining quota
+	// fails item by item so that already rejected items keep their own errors.
+	if err := s.reserveQuota(ctx, ownerID, len(pending)); err != nil {
+		if !errors.Is(err, quota.ErrLimitExceeded) {
+			return nil, err
+		}
+		for _, i := range pendingIdx {
+			results[i].Err = err
+		}
+		return results, nil
+	}
+
+	errs := s.cfg.LinksRepo.CreateLinks(ctx, pending)
+
+	created := make([]CacheEntry, 0, len(pending))
+	failed := 0
+	for j, link := range pending {
+		i := pendingIdx[j]
+
+		var err error
+		if errs[j] != nil {
+			err = fmt.Errorf("create link: %w", errs[j])
+		}
+		// A generated code that collided is retried individually; aliases are reported as taken.
+		if errors.Is(err, ErrAlreadyExists) && items[i].Alias == "" {
+			metrics.ShortCodeCollisionsTotal.Inc()
+			err = s.createWithGeneratedCode(ctx, link)
+		}
+		if err != nil {
+			results[i].Err = err
+			failed++
+			continue
+		}
+
+		results[i].Link = link
+		if ttl := cacheTTL(link, now); ttl > 0 {
+			created = append(created, CacheEntry{Link: link, TTL: ttl})
+		}
+	}
+
+	s.releaseQuota(ctx, ownerID, failed)
+
+	if s.cfg.LinkCache != nil && len(created) > 0 {
+		if err := s.cfg.LinkCache.SetManyByCode(ctx, created); err != nil {
+			s.cfg.Logger.Warn("failed to cache links after batch create",
+				logger.Error(err),
+				logger.Int("count", len(created)),
+			)
+		}
+	}
+
+	return results, nil
+}
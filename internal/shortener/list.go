@@ -0,0 +1,150 @@
+package shortener
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// ErrInvalidListParams is returned when list filters or the pagination cursor are malformed.
+var ErrInvalidListParams = errors.New("invalid list parameters")
+
+// LinkStatus filters links by their lifecycle state.
+type LinkStatus string
+
+const (
+	LinkStatusAll      LinkStatus = ""
+	LinkStatusActive   LinkStatus = "active"
+	LinkStatusInactive LinkStatus = "inactive"
+	LinkStatusExpired  LinkStatus = "expired"
+)
+
+// ListCursor is the keyset position after which the next page starts.
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// ListFilter describes a page query passed to Repository.ListLinks.
+// Links are ordered by (created_at, id) descending.
+type ListFilter struct {
+	// WorkspaceID lists the workspace's links; when nil the owner's personal links are listed.
+	WorkspaceID *int64
+	Status      LinkStatus
+	URLContains string
+	// CreatedFrom is inclusive, CreatedTo is exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	After       *ListCursor
+	Limit       int
+}
+
+// ListLinksParams describes a request to list an owner's links.
+type ListLinksParams struct {
+	// WorkspaceID lists a workspace's links instead of the owner's personal ones.
+	WorkspaceID *int64
+	Status      LinkStatus
+	URLContains string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Cursor is the opaque NextCursor of a previous page; empty for the first page.
+	Cursor string
+	Limit  int
+}
+
+// LinkPage is a single page of listed links.
+type LinkPage struct {
+	Links []*Link
+	// NextCursor is empty when there are no more pages.
+	NextCursor string
+}
+
+// ListLinks returns a page of links owned by ownerID matching the given filters.
+func (s *service) ListLinks(ctx context.Context, ownerID int64, params ListLinksParams) (*LinkPage, error) {
+	switch params.Status {
+	case LinkStatusAll, LinkStatusActive, LinkStatusInactive, LinkStatusExpired:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidListParams, params.Status)
+	}
+
+	if err := s.authorizeWorkspace(ctx, ownerID, params.WorkspaceID, workspace.ActionView); err != nil {
+		return nil, err
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	filter := ListFilter{
+		WorkspaceID: params.WorkspaceID,
+		Status:      params.Status,
+		URLContains: params.URLContains,
+		CreatedFrom: params.CreatedFrom,
+		CreatedTo:   params.CreatedTo,
+		Limit:       limit + 1,
+	}
+	if params.Cursor != "" {
+		cursor, err := decodeListCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	links, err := s.cfg.LinksRepo.ListLinks(ctx, ownerID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+
+	page := &LinkPage{Links: links}
+	if len(links) > limit {
+		page.Links = links[:limit]
+		last := page.Links[limit-1]
+		page.NextCursor = encodeListCursor(ListCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// encodeListCursor serializes a cursor into an opaque URL-safe token.
+func encodeListCursor(c ListCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeListCursor parses a token produced by encodeListCursor.
+func decodeListCursor(token string) (*ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListParams)
+	}
+
+	tsStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListParams)
+	}
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListParams)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListParams)
+	}
+
+	return &ListCursor{CreatedAt: time.Unix(0, ts).UTC(), ID: id}, nil
+}
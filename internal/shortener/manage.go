@@ -0,0 +1,83 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+)
+
+// ErrInvalidUpdate is returned when an update request carries no changes or invalid values.
+var ErrInvalidUpdate = errors.New("invalid update")
+
+// UpdateLinkParams describes a partial update of a link. Nil fields are left unchanged.
+type UpdateLinkParams struct {
+	OriginalURL *string
+	IsActive    *bool
+}
+
+// GetLink returns a link visible to ownerID. Links the user cannot see are reported as ErrNotFound.
+func (s *service) GetLink(ctx context.Context, ownerID, id int64) (*Link, error) {
+	return s.getAuthorizedLink(ctx, ownerID, id, workspace.ActionView)
+}
+
+// UpdateLink changes the destination URL and/or active flag of a link and invalidates its cache entry.
+func (s *service) UpdateLink(ctx context.Context, ownerID, id int64, params UpdateLinkParams) (*Link, error) {
+	if params.OriginalURL == nil && params.IsActive == nil {
+		return nil, fmt.Errorf("%w: nothing to update", ErrInvalidUpdate)
+	}
+
+	link, err := s.getAuthorizedLink(ctx, ownerID, id, workspace.ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.OriginalURL != nil {
+		originalURL, err := s.normalizeURL(*params.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		link.OriginalURL = originalURL
+	}
+	if params.IsActive != nil {
+		link.IsActive = *params.IsActive
+	}
+
+	if err := s.cfg.LinksRepo.UpdateLink(ctx, link); err != nil {
+		return nil, fmt.Errorf("update link: %w", err)
+	}
+
+	s.invalidateCache(ctx, link)
+	return link, nil
+}
+
+// DeleteLink removes a link and invalidates its cache entry.
+func (s *service) DeleteLink(ctx context.Context, ownerID, id int64) error {
+	link, err := s.getAuthorizedLink(ctx, ownerID, id, workspace.ActionEdit)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cfg.LinksRepo.DeleteLink(ctx, link.ID); err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+
+	s.invalidateCache(ctx, link)
+	return nil
+}
+
+// invalidateCache drops the cached copy of a link after it has been mutated.
+func (s *service) invalidateCache(ctx context.Context, link *Link) {
+	if s.cfg.LinkCache == nil {
+		return
+	}
+	if err := s.cfg.LinkCache.Delete(ctx, link.Domain, link.ShortCode); err != nil {
+		s.cfg.Logger.Warn("failed to invalidate cached link",
+			logger.Error(err),
+			logger.String("domain", link.Domain),
+			logger.String("code", link.ShortCode),
+		)
+	}
+}
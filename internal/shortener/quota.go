@@ -0,0 +1,51 @@
+package shortener
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+// QuotaEnforcer meters link creation against the owner's plan limits.
+type QuotaEnforcer interface {
+	CheckBatchSize(ctx context.Context, ownerID int64, n int) error
+	ReserveLinks(ctx context.Context, ownerID int64, n int) error
+	ReleaseLinks(ctx context.Context, ownerID int64, n int) error
+}
+
+// checkBatchQuota rejects batches the owner's plan does not allow. It is a no-op without quotas.
+func (s *service) checkBatchQuota(ctx context.Context, ownerID int64, n int) error {
+	if s.cfg.Quotas == nil {
+		return nil
+	}
+	if err := s.cfg.Quotas.CheckBatchSize(ctx, ownerID, n); err != nil {
+		return fmt.Errorf("check batch quota: %w", err)
+	}
+	return nil
+}
+
+// reserveQuota counts n links against the owner's monthly limit. It is a no-op without quotas.
+func (s *service) reserveQuota(ctx context.Context, ownerID int64, n int) error {
+	if s.cfg.Quotas == nil {
+		return nil
+	}
+	if err := s.cfg.Quotas.ReserveLinks(ctx, ownerID, n); err != nil {
+		return fmt.Errorf("reserve quota: %w", err)
+	}
+	return nil
+}
+
+// releaseQuota refunds n reserved links that were not created. Failures are only logged.
+func (s *service) releaseQuota(ctx context.Context, ownerID int64, n int) {
+	if s.cfg.Quotas == nil || n <= 0 {
+		return
+	}
+	if err := s.cfg.Quotas.ReleaseLinks(context.WithoutCancel(ctx), ownerID, n); err != nil {
+		s.cfg.Logger.Warn("failed to release link quota",
+			logger.Error(err),
+			logger.Int64("owner_id", ownerID),
+			logger.Int("count", n),
+		)
+	}
+}
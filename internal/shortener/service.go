@@ -7,14 +7,40 @@ import (
 	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+	"github.com/PavelKhromykhGo/url-shortener/metrics"
+	"golang.org/x/sync/singleflight"
 )
 
-var ErrNotFound = errors.New("link not found")
+var (
+	// ErrNotFound is returned when no link exists for the requested code.
+	ErrNotFound = errors.New("link not found")
+	// ErrAlreadyExists is returned by repositories when the (domain, short_code) pair is already taken.
+	ErrAlreadyExists = errors.New("short code already exists")
+	// ErrInvalidAlias is returned when a custom alias violates the alias policy.
+	ErrInvalidAlias = errors.New("invalid alias")
+	// ErrInvalidExpiration is returned when the requested expiration is in the past or ambiguous.
+	ErrInvalidExpiration = errors.New("invalid expiration")
+	// ErrExpired is returned when a link exists but its expiration time has passed.
+	ErrExpired = errors.New("link expired")
+	// ErrDisabled is returned when a link exists but has been deactivated.
+	ErrDisabled = errors.New("link disabled")
+	// ErrUnavailable is returned by repositories when the storage cannot be reached.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// defaultCacheTTL bounds how long a link stays cached when it has no earlier expiration.
+const defaultCacheTTL = 24 * time.Hour
+
+// loadTimeout bounds a shared database lookup in ResolveLink.
+const loadTimeout = 5 * time.Second
 
 // Link represents a shortened URL link.
 type Link struct {
-	ID          int64
-	OwnerID     int64
+	ID      int64
+	OwnerID int64
+	// WorkspaceID is set for links shared with a workspace; nil means a personal link.
+	WorkspaceID *int64
 	Domain      string
 	ShortCode   string
 	OriginalURL string
@@ -26,14 +52,39 @@ type Link struct {
 // Repository defines the interface for link storage.
 type Repository interface {
 	CreateLink(ctx context.Context, link *Link) error
+	// CreateLinks inserts links in bulk and returns one error per link, in order.
+	CreateLinks(ctx context.Context, links []*Link) []error
 	GetByCode(ctx context.Context, domain, code string) (*Link, error)
+	GetByID(ctx context.Context, id int64) (*Link, error)
+	// FindByOriginalURL returns the newest active, unexpired link for originalURL in the owner's
+	// personal space (workspaceID == nil) or in the given workspace, or ErrNotFound.
+	FindByOriginalURL(ctx context.Context, ownerID int64, workspaceID *int64, domain, originalURL string) (*Link, error)
+	UpdateLink(ctx context.Context, link *Link) error
+	DeleteLink(ctx context.Context, id int64) error
+	ListLinks(ctx context.Context, ownerID int64, filter ListFilter) ([]*Link, error)
+	// ListHotLinks returns up to limit usable links with the most clicks since the given day.
+	ListHotLinks(ctx context.Context, since time.Time, limit int) ([]*Link, error)
 }
 
 // LinkCache defines the interface for link caching.
 type LinkCache interface {
+	// GetByCode returns the cached link, ErrNotFound if the code is cached as not found,
+	// or (nil, nil) on a cache miss.
 	GetByCode(ctx context.Context, domain, code string) (*Link, error)
+	// SetByCode caches a link and clears its not-found marker.
 	SetByCode(ctx context.Context, link *Link, ttl time.Duration) error
+	SetManyByCode(ctx context.Context, entries []CacheEntry) error
+	// GetStaleByCode returns the long-lived copy of a link kept for outages, or (nil, nil).
+	GetStaleByCode(ctx context.Context, domain, code string) (*Link, error)
+	// SetNotFound caches the absence of a code for ttl.
 	SetNotFound(ctx context.Context, domain, code string, ttl time.Duration) error
+	Delete(ctx context.Context, domain, code string) error
+}
+
+// CacheEntry pairs a link with the TTL it should be cached for.
+type CacheEntry struct {
+	Link *Link
+	TTL  time.Duration
 }
 
 // IDGenerator defines the interface for generating short codes.
@@ -41,6 +92,9 @@ type IDGenerator interface {
 	GenerateShortCode() (string, error)
 }
 
+// defaultMaxCodeAttempts is used when Config.MaxCodeAttempts is not set.
+const defaultMaxCodeAttempts = 5
+
 // Config holds the configuration for the shortener service.
 type Config struct {
 	BaseURL   string
@@ -48,18 +102,57 @@ type Config struct {
 	LinkCache LinkCache
 	IDGen     IDGenerator
 	Logger    logger.Logger
+	// MaxCodeAttempts limits how many generated codes are tried before giving up on collisions.
+	MaxCodeAttempts int
+	// AllowedSchemes lists destination URL schemes accepted on creation; defaults to http and https.
+	AllowedSchemes []string
+	// MaxURLLength caps the length of destination URLs; defaults to 2048.
+	MaxURLLength int
+	// OwnDomains lists extra hosts, besides the BaseURL host, that destinations must not point to.
+	OwnDomains []string
+	// Workspaces authorizes access to workspace links.
+	Workspaces WorkspaceAuthorizer
+	// NotFoundTTL is how long unknown codes are cached as not found; non-positive disables it.
+	NotFoundTTL time.Duration
+	// Quotas enforces per-owner plan limits; nil disables them.
+	Quotas QuotaEnforcer
+}
+
+// CreateLinkParams describes a request to create a short link.
+type CreateLinkParams struct {
+	OriginalURL string
+	// Alias is an optional custom short code; a random code is generated when empty.
+	Alias string
+	// ExpiresAt is an optional absolute expiration time. Mutually exclusive with TTL.
+	ExpiresAt *time.Time
+	// TTL is an optional lifetime counted from creation. Mutually exclusive with ExpiresAt.
+	TTL time.Duration
+	// Dedup returns the owner's existing usable link for the same normalized URL instead of
+	// creating a new one. It is ignored when Alias is set.
+	Dedup bool
+	// WorkspaceID creates the link in a workspace instead of the owner's personal space.
+	WorkspaceID *int64
 }
 
 // Service defines the interface for the shortener service.
 type Service interface {
-	CreateShortLink(ctx context.Context, ownerID int64, originalURL string) (*Link, error)
+	CreateShortLink(ctx context.Context, ownerID int64, params CreateLinkParams) (*Link, error)
+	CreateShortLinks(ctx context.Context, ownerID int64, items []CreateLinkParams) ([]BatchResult, error)
 	ResolveLink(ctx context.Context, domain, code string) (*Link, error)
 	BuildShortURL(link *Link) string
+	GetLink(ctx context.Context, ownerID, id int64) (*Link, error)
+	UpdateLink(ctx context.Context, ownerID, id int64, params UpdateLinkParams) (*Link, error)
+	DeleteLink(ctx context.Context, ownerID, id int64) error
+	ListLinks(ctx context.Context, ownerID int64, params ListLinksParams) (*LinkPage, error)
+	TransferLink(ctx context.Context, ownerID, id, workspaceID int64) (*Link, error)
+	CheckLinkAccess(ctx context.Context, ownerID, id int64) error
+	WarmCache(ctx context.Context, since time.Time, limit int) (int, error)
 }
 
 // service is the implementation of the Service interface.
 type service struct {
-	cfg Config
+	cfg   Config
+	loads singleflight.Group
 }
 
 // NewService creates a new shortener service.
@@ -68,30 +161,44 @@ func NewService(cfg Config) Service {
 }
 
 // CreateShortLink creates a new shortened link.
-func (s *service) CreateShortLink(ctx context.Context, ownerID int64, originalURL string) (*Link, error) {
-	code, err := s.cfg.IDGen.GenerateShortCode()
+func (s *service) CreateShortLink(ctx context.Context, ownerID int64, params CreateLinkParams) (*Link, error) {
+	now := time.Now().UTC()
+
+	link, err := s.prepareLink(ownerID, params, now)
 	if err != nil {
-		return nil, fmt.Errorf("generate short code: %w", err)
+		return nil, err
 	}
 
-	now := time.Now().UTC()
+	if err := s.authorizeWorkspace(ctx, ownerID, params.WorkspaceID, workspace.ActionEdit); err != nil {
+		return nil, err
+	}
 
-	link := &Link{
-		OwnerID:     ownerID,
-		Domain:      s.cfg.BaseURL,
-		ShortCode:   code,
-		OriginalURL: originalURL,
-		//ExpiresAt: nil
-		IsActive:  true,
-		CreatedAt: now,
+	if params.Dedup && params.Alias == "" {
+		existing, err := s.cfg.LinksRepo.FindByOriginalURL(ctx, ownerID, link.WorkspaceID, link.Domain, link.OriginalURL)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("find link by original url: %w", err)
+		}
 	}
 
-	if err = s.cfg.LinksRepo.CreateLink(ctx, link); err != nil {
-		return nil, fmt.Errorf("create link: %w", err)
+	if err := s.reserveQuota(ctx, ownerID, 1); err != nil {
+		return nil, err
 	}
 
-	if s.cfg.LinkCache != nil {
-		if err = s.cfg.LinkCache.SetByCode(ctx, link, 24*time.Hour); err != nil {
+	if link.ShortCode != "" {
+		if err = s.cfg.LinksRepo.CreateLink(ctx, link); err != nil {
+			s.releaseQuota(ctx, ownerID, 1)
+			return nil, fmt.Errorf("create link: %w", err)
+		}
+	} else if err = s.createWithGeneratedCode(ctx, link); err != nil {
+		s.releaseQuota(ctx, ownerID, 1)
+		return nil, err
+	}
+
+	if ttl := cacheTTL(link, now); s.cfg.LinkCache != nil && ttl > 0 {
+		if err = s.cfg.LinkCache.SetByCode(ctx, link, ttl); err != nil {
 			s.cfg.Logger.Warn("failed to cache link after create",
 				logger.Error(err),
 				logger.String("code", link.ShortCode),
@@ -102,10 +209,80 @@ func (s *service) CreateShortLink(ctx context.Context, ownerID int64, originalUR
 	return link, nil
 }
 
+// prepareLink validates creation parameters and builds the link to insert.
+// ShortCode is set only for custom aliases; otherwise the caller generates it.
+func (s *service) prepareLink(ownerID int64, params CreateLinkParams, now time.Time) (*Link, error) {
+	originalURL, err := s.normalizeURL(params.OriginalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := resolveExpiration(params, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Alias != "" {
+		if err := validateAlias(params.Alias); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Link{
+		OwnerID:     ownerID,
+		WorkspaceID: params.WorkspaceID,
+		Domain:      s.cfg.BaseURL,
+		ShortCode:   params.Alias,
+		OriginalURL: originalURL,
+		ExpiresAt:   expiresAt,
+		IsActive:    true,
+		CreatedAt:   now,
+	}, nil
+}
+
+// createWithGeneratedCode inserts link under a freshly generated short code,
+// regenerating the code when it collides with an existing one.
+func (s *service) createWithGeneratedCode(ctx context.Context, link *Link) error {
+	attempts := s.cfg.MaxCodeAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxCodeAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		code, err := s.cfg.IDGen.GenerateShortCode()
+		if err != nil {
+			return fmt.Errorf("generate short code: %w", err)
+		}
+		link.ShortCode = code
+
+		err = s.cfg.LinksRepo.CreateLink(ctx, link)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrAlreadyExists) {
+			return fmt.Errorf("create link: %w", err)
+		}
+
+		metrics.ShortCodeCollisionsTotal.Inc()
+		if attempt >= attempts {
+			metrics.ShortCodeRetriesExhaustedTotal.Inc()
+			return fmt.Errorf("create link: no free short code after %d attempts", attempt)
+		}
+
+		s.cfg.Logger.Warn("short code collision, retrying",
+			logger.String("code", code),
+			logger.Int("attempt", attempt),
+		)
+	}
+}
+
 // ResolveLink resolves a shortened link by its code.
 func (s *service) ResolveLink(ctx context.Context, domain, code string) (*Link, error) {
 	if s.cfg.LinkCache != nil {
 		link, err := s.cfg.LinkCache.GetByCode(ctx, domain, code)
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
 		if err != nil {
 			s.cfg.Logger.Warn("failed to get link by code",
 				logger.Error(err),
@@ -113,20 +290,50 @@ func (s *service) ResolveLink(ctx context.Context, domain, code string) (*Link,
 				logger.String("code", code),
 			)
 		} else if link != nil {
-			if !isLinkUsable(link) {
-				return nil, fmt.Errorf("link is not active or expired")
+			if err := checkLinkUsable(link, time.Now()); err != nil {
+				return nil, err
 			}
 			return link, nil
 		}
 	}
 
+	// Concurrent misses for the same code share one database lookup. The load is detached
+	// from the caller's cancellation because other callers may be waiting for it.
+	v, err, _ := s.loads.Do(domain+"\x00"+code, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		return s.loadLink(loadCtx, domain, code)
+	})
+	if errors.Is(err, ErrUnavailable) {
+		// Keep redirects working through database outages with the long-lived cached copy.
+		if stale := s.staleLink(ctx, domain, code); stale != nil {
+			v, err = stale, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	link := v.(*Link)
+
+	now := time.Now()
+	if err := checkLinkUsable(link, now); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// loadLink reads a link from the repository and caches the outcome.
+func (s *service) loadLink(ctx context.Context, domain, code string) (*Link, error) {
 	link, err := s.cfg.LinksRepo.GetByCode(ctx, domain, code)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			s.cacheNotFound(ctx, domain, code)
+		}
 		return nil, fmt.Errorf("get link by code: %w", err)
 	}
 
-	if s.cfg.LinkCache != nil {
-		if err := s.cfg.LinkCache.SetByCode(ctx, link, 24*time.Hour); err != nil {
+	if ttl := cacheTTL(link, time.Now()); s.cfg.LinkCache != nil && ttl > 0 {
+		if err := s.cfg.LinkCache.SetByCode(ctx, link, ttl); err != nil {
 			s.cfg.Logger.Warn("failed to cache link after resolve",
 				logger.Error(err),
 				logger.String("domain", domain),
@@ -134,11 +341,48 @@ func (s *service) ResolveLink(ctx context.Context, domain, code string) (*Link,
 			)
 		}
 	}
+	return link, nil
+}
 
-	if !isLinkUsable(link) {
-		return nil, fmt.Errorf("link is not active or expired")
+// staleLink returns the stale cached copy of a link, or nil when there is none.
+func (s *service) staleLink(ctx context.Context, domain, code string) *Link {
+	if s.cfg.LinkCache == nil {
+		return nil
+	}
+
+	link, err := s.cfg.LinkCache.GetStaleByCode(ctx, domain, code)
+	if err != nil {
+		s.cfg.Logger.Warn("failed to get stale link by code",
+			logger.Error(err),
+			logger.String("domain", domain),
+			logger.String("code", code),
+		)
+		return nil
+	}
+	if link == nil {
+		return nil
+	}
+
+	metrics.StaleLinksServedTotal.Inc()
+	s.cfg.Logger.Warn("storage unavailable, serving stale link",
+		logger.String("domain", domain),
+		logger.String("code", code),
+	)
+	return link
+}
+
+// cacheNotFound marks code as not found so repeated lookups of unknown codes skip the database.
+func (s *service) cacheNotFound(ctx context.Context, domain, code string) {
+	if s.cfg.LinkCache == nil || s.cfg.NotFoundTTL <= 0 {
+		return
+	}
+	if err := s.cfg.LinkCache.SetNotFound(ctx, domain, code, s.cfg.NotFoundTTL); err != nil {
+		s.cfg.Logger.Warn("failed to cache link as not found",
+			logger.Error(err),
+			logger.String("domain", domain),
+			logger.String("code", code),
+		)
 	}
-	return link, nil
 }
 
 // BuildShortURL constructs the full short URL from a Link.
@@ -146,15 +390,46 @@ func (s *service) BuildShortURL(link *Link) string {
 	return fmt.Sprintf("%s/%s", link.Domain, link.ShortCode)
 }
 
-// isLinkUsable checks if a link is active and not expired.
-func isLinkUsable(link *Link) bool {
+// checkLinkUsable returns an error if a link is inactive or expired at the given time.
+func checkLinkUsable(link *Link, now time.Time) error {
 	if !link.IsActive {
-		return false
+		return ErrDisabled
+	}
+	if link.ExpiresAt != nil && !now.Before(*link.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// resolveExpiration turns the ExpiresAt/TTL inputs into an absolute expiration time, if any.
+func resolveExpiration(params CreateLinkParams, now time.Time) (*time.Time, error) {
+	switch {
+	case params.ExpiresAt != nil && params.TTL != 0:
+		return nil, fmt.Errorf("%w: expires_at and ttl are mutually exclusive", ErrInvalidExpiration)
+	case params.ExpiresAt != nil:
+		if !params.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidExpiration)
+		}
+		expiresAt := params.ExpiresAt.UTC()
+		return &expiresAt, nil
+	case params.TTL != 0:
+		if params.TTL < 0 {
+			return nil, fmt.Errorf("%w: ttl must be positive", ErrInvalidExpiration)
+		}
+		expiresAt := now.Add(params.TTL).UTC()
+		return &expiresAt, nil
 	}
+	return nil, nil
+}
+
+// cacheTTL caps the default cache TTL at the link's remaining lifetime.
+// A non-positive result means the link must not be cached.
+func cacheTTL(link *Link, now time.Time) time.Duration {
+	ttl := defaultCacheTTL
 	if link.ExpiresAt != nil {
-		if time.Now().After(*link.ExpiresAt) {
-			return false
+		if remaining := link.ExpiresAt.Sub(now); remaining < ttl {
+			ttl = remaining
 		}
 	}
-	return true
+	return ttl
 }
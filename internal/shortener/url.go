@@ -0,0 +1,109 @@
+package shortener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const defaultMaxURLLength = 2048
+
+// defaultAllowedSchemes is used when Config.AllowedSchemes is empty.
+var defaultAllowedSchemes = []string{"http", "https"}
+
+// ErrInvalidURL is returned when a destination URL fails validation.
+var ErrInvalidURL = errors.New("invalid URL")
+
+// normalizeURL validates a destination URL and returns its canonical form:
+// an absolute URL with an allowed scheme, a lowercase punycode host, within the length
+// limit and not pointing back at one of the service's own domains.
+func (s *service) normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: must not be empty", ErrInvalidURL)
+	}
+
+	maxLen := s.cfg.MaxURLLength
+	if maxLen <= 0 {
+		maxLen = defaultMaxURLLength
+	}
+	if len(raw) > maxLen {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidURL, maxLen)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !s.schemeAllowed(u.Scheme) {
+		return "", fmt.Errorf("%w: scheme %q is not allowed", ErrInvalidURL, u.Scheme)
+	}
+
+	hostname := u.Hostname()
+	if hostname == "" {
+		return "", fmt.Errorf("%w: host is required", ErrInvalidURL)
+	}
+
+	asciiHost := strings.ToLower(hostname)
+	if net.ParseIP(asciiHost) == nil {
+		if asciiHost, err = idna.Lookup.ToASCII(asciiHost); err != nil {
+			return "", fmt.Errorf("%w: invalid host %q", ErrInvalidURL, hostname)
+		}
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(asciiHost, port)
+	} else if strings.Contains(asciiHost, ":") {
+		u.Host = "[" + asciiHost + "]"
+	} else {
+		u.Host = asciiHost
+	}
+
+	if s.isOwnHost(asciiHost) {
+		return "", fmt.Errorf("%w: must not point to the shortener itself", ErrInvalidURL)
+	}
+
+	normalized := u.String()
+	if len(normalized) > maxLen {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidURL, maxLen)
+	}
+	return normalized, nil
+}
+
+// schemeAllowed reports whether scheme is in the configured allowlist.
+func (s *service) schemeAllowed(scheme string) bool {
+	allowed := s.cfg.AllowedSchemes
+	if len(allowed) == 0 {
+		allowed = defaultAllowedSchemes
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnHost reports whether host is the BaseURL host or one of the configured own domains.
+func (s *service) isOwnHost(host string) bool {
+	if u, err := url.Parse(s.cfg.BaseURL); err == nil && sameHost(u.Hostname(), host) {
+		return true
+	}
+	for _, d := range s.cfg.OwnDomains {
+		if sameHost(strings.TrimSpace(d), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameHost compares host names case-insensitively, treating fully qualified names
+// with a trailing dot as equal to their relative form.
+func sameHost(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
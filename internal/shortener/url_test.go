@@ -0,0 +1,50 @@
+package shortener
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	s := &service{cfg: Config{
+		BaseURL:    "https://sho.rt",
+		OwnDomains: []string{"go.example.com."},
+	}}
+
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "https://Example.COM/Path?q=1", want: "https://example.com/Path?q=1"},
+		{raw: "  http://example.com:8080/x  ", want: "http://example.com:8080/x"},
+		{raw: "https://bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{raw: "javascript:alert(1)", wantErr: true},
+		{raw: "data:text/html,hi", wantErr: true},
+		{raw: "/relative/path", wantErr: true},
+		{raw: "https:///no-host", wantErr: true},
+		{raw: "https://sho.rt/abc", wantErr: true},
+		{raw: "https://SHO.RT/abc", wantErr: true},
+		{raw: "https://sho.rt./abc", wantErr: true},
+		{raw: "https://go.example.com/x", wantErr: true},
+		{raw: "https://go.example.com./x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := s.normalizeURL(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidURL) {
+					t.Errorf("normalizeURL(%q) = %q, %v; want %v", tt.raw, got, err, ErrInvalidURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeURL(%q) error = %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package shortener
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WarmCache loads the limit most clicked links since the given day into the cache and
+// returns how many were cached.
+func (s *service) WarmCache(ctx context.Context, since time.Time, limit int) (int, error) {
+	if s.cfg.LinkCache == nil || limit <= 0 {
+		return 0, nil
+	}
+
+	links, err := s.cfg.LinksRepo.ListHotLinks(ctx, since, limit)
+	if err != nil {
+		return 0, fmt.Errorf("list hot links: %w", err)
+	}
+
+	now := time.Now()
+	entries := make([]CacheEntry, 0, len(links))
+	for _, link := range links {
+		if ttl := cacheTTL(link, now); ttl > 0 {
+			entries = append(entries, CacheEntry{Link: link, TTL: ttl})
+		}
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	if err := s.cfg.LinkCache.SetManyByCode(ctx, entries); err != nil {
+		return 0, fmt.Errorf("cache hot links: %w", err)
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/auth"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeysRepository is the Postgres implementation of the auth.APIKeyRepository interface.
+type APIKeysRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPIKeysRepository creates a new instance of APIKeysRepository.
+func NewAPIKeysRepository(pool *pgxpool.Pool) *APIKeysRepository {
+	return &APIKeysRepository{pool: pool}
+}
+
+var _ auth.APIKeyRepository = (*APIKeysRepository)(nil)
+
+const insertAPIKeyQuery = `
+INSERT INTO api_keys (owner_id, name, key_prefix, key_hash)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at
+`
+
+const getActiveAPIKeyByHashQuery = `
+SELECT id, owner_id, name, key_prefix, key_hash, created_at, revoked_at
+FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL
+`
+
+const revokeAPIKeyQuery = `
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+`
+
+// CreateAPIKey inserts a new API key.
+func (r *APIKeysRepository) CreateAPIKey(ctx context.Context, key *auth.APIKey) error {
+	row := r.pool.QueryRow(ctx, insertAPIKeyQuery,
+		key.OwnerID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+	)
+	return row.Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetActiveAPIKeyByHash retrieves a non-revoked API key by the hash of its secret.
+func (r *APIKeysRepository) GetActiveAPIKeyByHash(ctx context.Context, hash string) (*auth.APIKey, error) {
+	var key auth.APIKey
+	if err := r.pool.QueryRow(ctx, getActiveAPIKeyByHashQuery, hash).Scan(
+		&key.ID,
+		&key.OwnerID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, auth.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *APIKeysRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, revokeAPIKeyQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/id"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CodePoolRepository is the Postgres implementation of the id.CodeStore interface.
+type CodePoolRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCodePoolRepository creates a new instance of CodePoolRepository.
+func NewCodePoolRepository(pool *pgxpool.Pool) *CodePoolRepository {
+	return &CodePoolRepository{pool: pool}
+}
+
+var _ id.CodeStore = (*CodePoolRepository)(nil)
+
+// reserveCodesQuery reserves the candidates that are neither reserved nor used by a link
+// and returns them; the rest are silently skipped.
+const reserveCodesQuery = `
+INSERT INTO short_code_reservations (domain, short_code)
+SELECT $1, c.code
+FROM unnest($2::text[]) AS c(code)
+WHERE NOT EXISTS (
+    SELECT 1 FROM links l WHERE l.domain = $1 AND l.short_code = c.code
+)
+ON CONFLICT (domain, short_code) DO NOTHING
+RETURNING short_code
+`
+
+const releaseCodesQuery = `
+DELETE FROM short_code_reservations
+WHERE domain = $1 AND short_code = ANY($2::text[])
+`
+
+const deleteExpiredReservationsQuery = `
+DELETE FROM short_code_reservations
+WHERE reserved_at < $1
+`
+
+// ReserveCodes reserves the given codes for domain and returns the ones that were free.
+func (r *CodePoolRepository) ReserveCodes(ctx context.Context, domain string, codes []string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, reserveCodesQuery, domain, codes)
+	if err != nil {
+		return nil, fmt.Errorf("reserve codes: %w", err)
+	}
+	defer rows.Close()
+
+	reserved := make([]string, 0, len(codes))
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("scan code: %w", err)
+		}
+		reserved = append(reserved, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate codes: %w", err)
+	}
+	return reserved, nil
+}
+
+// ReleaseCodes deletes the reservations of codes that were never used.
+func (r *CodePoolRepository) ReleaseCodes(ctx context.Context, domain string, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	if _, err := r.pool.Exec(ctx, releaseCodesQuery, domain, codes); err != nil {
+		return fmt.Errorf("release codes: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredReservations deletes reservations made before the given time. Codes that ended
+// up in links stay protected by the links unique index.
+func (r *CodePoolRepository) DeleteExpiredReservations(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, deleteExpiredReservationsQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired reservations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/id"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CodeSequenceRepository is the Postgres implementation of the id.Sequence interface.
+type CodeSequenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCodeSequenceRepository creates a new instance of CodeSequenceRepository.
+func NewCodeSequenceRepository(pool *pgxpool.Pool) *CodeSequenceRepository {
+	return &CodeSequenceRepository{pool: pool}
+}
+
+var _ id.Sequence = (*CodeSequenceRepository)(nil)
+
+// reserveIDsQuery draws $1 values from the sequence in one round trip.
+// Values are unique across instances but not necessarily contiguous.
+const reserveIDsQuery = `
+SELECT nextval('short_code_seq')
+FROM generate_series(1, $1)
+`
+
+// ReserveIDs reserves n IDs from short_code_seq. IDs that are never used are simply skipped.
+func (r *CodeSequenceRepository) ReserveIDs(ctx context.Context, n int) ([]int64, error) {
+	rows, err := r.pool.Query(ctx, reserveIDsQuery, n)
+	if err != nil {
+		return nil, fmt.Errorf("reserve ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan id: %w", err)
+		}
+		ids = append(ids, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate ids: %w", err)
+	}
+	return ids, nil
+}
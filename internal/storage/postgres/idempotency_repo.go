@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/idempotency"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyRepository is the Postgres implementation of the idempotency.Store interface.
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository.
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+var (
+	_ idempotency.Store  = (*IdempotencyRepository)(nil)
+	_ idempotency.Purger = (*IdempotencyRepository)(nil)
+)
+
+// reserveIdempotencyKeyQuery inserts a new reservation or takes over an expired one.
+const reserveIdempotencyKeyQuery = `
+INSERT INTO idempotency_keys (owner_id, key, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (owner_id, key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING true
+`
+
+const getIdempotencyKeyQuery = `
+SELECT request_hash, status_code, response
+FROM idempotency_keys
+WHERE owner_id = $1 AND key = $2
+`
+
+const completeIdempotencyKeyQuery = `
+UPDATE idempotency_keys
+SET status_code = $3, response = $4
+WHERE owner_id = $1 AND key = $2
+`
+
+const releaseIdempotencyKeyQuery = `
+DELETE FROM idempotency_keys
+WHERE owner_id = $1 AND key = $2 AND status_code IS NULL
+`
+
+// purgeExpiredIdempotencyKeysQuery deletes up to $1 expired keys so that a large backlog
+// is removed in short transactions.
+const purgeExpiredIdempotencyKeysQuery = `
+DELETE FROM idempotency_keys
+WHERE (owner_id, key) IN (
+    SELECT owner_id, key
+    FROM idempotency_keys
+    WHERE expires_at <= now()
+    LIMIT $1
+)
+`
+
+// Reserve claims an idempotency key or returns the record that already holds it.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, ownerID int64, key, requestHash string, expiresAt time.Time) (*idempotency.Record, bool, error) {
+	var reserved bool
+	err := r.pool.QueryRow(ctx, reserveIdempotencyKeyQuery, ownerID, key, requestHash, expiresAt).Scan(&reserved)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, err
+	}
+
+	var (
+		rec        idempotency.Record
+		statusCode *int
+	)
+	if err := r.pool.QueryRow(ctx, getIdempotencyKeyQuery, ownerID, key).Scan(
+		&rec.RequestHash,
+		&statusCode,
+		&rec.Response,
+	); err != nil {
+		return nil, false, err
+	}
+	if statusCode != nil {
+		rec.StatusCode = *statusCode
+	}
+	return &rec, false, nil
+}
+
+// Complete stores the response of a reserved key.
+func (r *IdempotencyRepository) Complete(ctx context.Context, ownerID int64, key string, statusCode int, response []byte) error {
+	_, err := r.pool.Exec(ctx, completeIdempotencyKeyQuery, ownerID, key, statusCode, response)
+	return err
+}
+
+// Release removes an uncompleted reservation.
+func (r *IdempotencyRepository) Release(ctx context.Context, ownerID int64, key string) error {
+	_, err := r.pool.Exec(ctx, releaseIdempotencyKeyQuery, ownerID, key)
+	return err
+}
+
+// PurgeExpired deletes up to limit expired keys and returns how many were deleted.
+func (r *IdempotencyRepository) PurgeExpired(ctx context.Context, limit int) (int64, error) {
+	tag, err := r.pool.Exec(ctx, purgeExpiredIdempotencyKeysQuery, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
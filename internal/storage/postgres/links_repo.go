@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
 	"github.com/jackc/pgx/v5"
@@ -20,18 +23,73 @@ func NewLinksRepository(pool *pgxpool.Pool) *LinksRepository {
 }
 
 const insertLinkQuery = `
-INSERT INTO links (owner_id, domain, short_code, original_url, expires_at, is_active)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO links (owner_id, domain, short_code, original_url, expires_at, is_active, workspace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at
+`
+
+// insertLinkIgnoreConflictQuery is used by batches: a taken short code yields no row
+// instead of an error, so one conflict does not abort the rest of the pipeline.
+const insertLinkIgnoreConflictQuery = `
+INSERT INTO links (owner_id, domain, short_code, original_url, expires_at, is_active, workspace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (domain, short_code) DO NOTHING
 RETURNING id, created_at
 `
 
 const getByCodeQuery = `
-SELECT id, owner_id, domain, short_code, original_url, expires_at, is_active, created_at
+SELECT id, owner_id, workspace_id, domain, short_code, original_url, expires_at, is_active, created_at
 FROM links
 WHERE domain = $1 AND short_code = $2
 `
 
+const findByOriginalURLQuery = `
+SELECT id, owner_id, workspace_id, domain, short_code, original_url, expires_at, is_active, created_at
+FROM links
+WHERE domain = $3 AND md5(original_url) = md5($4) AND original_url = $4
+  AND ((workspace_id IS NULL AND owner_id = $1) OR workspace_id = $2)
+  AND is_active AND (expires_at IS NULL OR expires_at > now())
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+const getByIDQuery = `
+SELECT id, owner_id, workspace_id, domain, short_code, original_url, expires_at, is_active, created_at
+FROM links
+WHERE id = $1
+`
+
+// listHotLinksQuery ranks links by clicks since $1 and returns the top $2 that can still be redirected.
+// Dead links are filtered out before the limit so that they do not take places in the ranking.
+const listHotLinksQuery = `
+SELECT l.id, l.owner_id, l.workspace_id, l.domain, l.short_code, l.original_url, l.expires_at, l.is_active, l.created_at
+FROM (
+    SELECT s.link_id, SUM(s.count) AS clicks
+    FROM click_stats_daily s
+    JOIN links l ON l.id = s.link_id
+    WHERE s.date >= $1
+      AND l.is_active AND (l.expires_at IS NULL OR l.expires_at > now())
+    GROUP BY s.link_id
+    ORDER BY clicks DESC
+    LIMIT $2
+) hot
+JOIN links l ON l.id = hot.link_id
+ORDER BY hot.clicks DESC
+`
+
+const updateLinkQuery = `
+UPDATE links
+SET original_url = $2, is_active = $3, workspace_id = $4
+WHERE id = $1
+`
+
+const deleteLinkQuery = `
+DELETE FROM links
+WHERE id = $1
+`
+
 // CreateLink inserts a new link into the database.
+// It returns shortener.ErrAlreadyExists if the (domain, short_code) pair is already taken.
 func (r *LinksRepository) CreateLink(ctx context.Context, link *shortener.Link) error {
 	row := r.pool.QueryRow(ctx, insertLinkQuery,
 		link.OwnerID,
@@ -40,21 +98,234 @@ func (r *LinksRepository) CreateLink(ctx context.Context, link *shortener.Link)
 		link.OriginalURL,
 		link.ExpiresAt,
 		link.IsActive,
+		link.WorkspaceID,
 	)
 	if err := row.Scan(&link.ID, &link.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return shortener.ErrAlreadyExists
+		}
 		return err
 	}
 	return nil
 }
 
+// CreateLinks inserts links in a single Postgres batch and returns one error per link.
+// A taken (domain, short_code) pair is reported as shortener.ErrAlreadyExists for that link only.
+func (r *LinksRepository) CreateLinks(ctx context.Context, links []*shortener.Link) []error {
+	return createLinksBatch(ctx, r.pool, links)
+}
+
+// batchSender is the part of pgxpool.Pool used to send batches.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// createLinksBatch inserts links in one pipeline. Postgres runs a pipeline as a single implicit
+// transaction, so any error other than a conflict rolls back every insert of the batch; all
+// links then fail with that error instead of being reported as created.
+func createLinksBatch(ctx context.Context, sender batchSender, links []*shortener.Link) []error {
+	errs := make([]error, len(links))
+	if len(links) == 0 {
+		return errs
+	}
+
+	batch := &pgx.Batch{}
+	for _, link := range links {
+		batch.Queue(insertLinkIgnoreConflictQuery,
+			link.OwnerID,
+			link.Domain,
+			link.ShortCode,
+			link.OriginalURL,
+			link.ExpiresAt,
+			link.IsActive,
+			link.WorkspaceID,
+		)
+	}
+
+	var batchErr error
+	br := sender.SendBatch(ctx, batch)
+	for i, link := range links {
+		err := br.QueryRow().Scan(&link.ID, &link.CreatedAt)
+		switch {
+		case err == nil:
+		case errors.Is(err, pgx.ErrNoRows):
+			errs[i] = shortener.ErrAlreadyExists
+		default:
+			errs[i] = err
+			if batchErr == nil {
+				batchErr = err
+			}
+		}
+	}
+	if err := br.Close(); err != nil && batchErr == nil {
+		batchErr = err
+	}
+
+	if batchErr != nil {
+		for i, link := range links {
+			link.ID, link.CreatedAt = 0, time.Time{}
+			if errs[i] == nil || errors.Is(errs[i], shortener.ErrAlreadyExists) {
+				errs[i] = fmt.Errorf("batch rolled back: %w", batchErr)
+			}
+		}
+	}
+	return errs
+}
+
 // GetByCode retrieves a link by its domain and short code.
+// Connection failures are reported as shortener.ErrUnavailable.
 func (r *LinksRepository) GetByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
-	var link shortener.Link
-	row := r.pool.QueryRow(ctx, getByCodeQuery, domain, code)
+	link, err := scanLink(r.pool.QueryRow(ctx, getByCodeQuery, domain, code))
+	if err != nil && isUnavailable(err) {
+		return nil, fmt.Errorf("%w: %w", shortener.ErrUnavailable, err)
+	}
+	return link, err
+}
+
+// FindByOriginalURL returns the newest usable link on domain pointing to originalURL, searching
+// the owner's personal links or, when workspaceID is set, the workspace's links.
+func (r *LinksRepository) FindByOriginalURL(ctx context.Context, ownerID int64, workspaceID *int64, domain, originalURL string) (*shortener.Link, error) {
+	if workspaceID != nil {
+		ownerID = 0
+	}
+	return scanLink(r.pool.QueryRow(ctx, findByOriginalURLQuery, ownerID, workspaceID, domain, originalURL))
+}
+
+// GetByID retrieves a link by its identifier.
+func (r *LinksRepository) GetByID(ctx context.Context, id int64) (*shortener.Link, error) {
+	return scanLink(r.pool.QueryRow(ctx, getByIDQuery, id))
+}
+
+// UpdateLink persists the mutable fields of a link (destination URL and active flag).
+func (r *LinksRepository) UpdateLink(ctx context.Context, link *shortener.Link) error {
+	tag, err := r.pool.Exec(ctx, updateLinkQuery,
+		link.ID,
+		link.OriginalURL,
+		link.IsActive,
+		link.WorkspaceID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return shortener.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteLink removes a link and, through cascading foreign keys, its analytics.
+func (r *LinksRepository) DeleteLink(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, deleteLinkQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return shortener.ErrNotFound
+	}
+	return nil
+}
+
+const listLinksBaseQuery = `
+SELECT id, owner_id, workspace_id, domain, short_code, original_url, expires_at, is_active, created_at
+FROM links
+WHERE `
+
+// likeEscaper escapes LIKE wildcards so user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListLinks returns an owner's personal links, or a workspace's links when filter.WorkspaceID is set,
+// matching filter using keyset pagination over (created_at, id).
+func (r *LinksRepository) ListLinks(ctx context.Context, ownerID int64, filter shortener.ListFilter) ([]*shortener.Link, error) {
+	var sb strings.Builder
+	sb.WriteString(listLinksBaseQuery)
+	var args []any
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.WorkspaceID != nil {
+		sb.WriteString("workspace_id = " + arg(*filter.WorkspaceID))
+	} else {
+		sb.WriteString("owner_id = " + arg(ownerID) + " AND workspace_id IS NULL")
+	}
+
+	switch filter.Status {
+	case shortener.LinkStatusActive:
+		sb.WriteString(" AND is_active AND (expires_at IS NULL OR expires_at > now())")
+	case shortener.LinkStatusInactive:
+		sb.WriteString(" AND NOT is_active")
+	case shortener.LinkStatusExpired:
+		sb.WriteString(" AND expires_at IS NOT NULL AND expires_at <= now()")
+	}
+	if filter.URLContains != "" {
+		sb.WriteString(" AND original_url ILIKE '%' || " + arg(likeEscaper.Replace(filter.URLContains)) + " || '%'")
+	}
+	if filter.CreatedFrom != nil {
+		sb.WriteString(" AND created_at >= " + arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		sb.WriteString(" AND created_at < " + arg(*filter.CreatedTo))
+	}
+	if filter.After != nil {
+		sb.WriteString(" AND (created_at, id) < (" + arg(filter.After.CreatedAt) + ", " + arg(filter.After.ID) + ")")
+	}
+	sb.WriteString(" ORDER BY created_at DESC, id DESC LIMIT " + arg(filter.Limit))
 
+	rows, err := r.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*shortener.Link, 0, filter.Limit)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// ListHotLinks returns up to limit usable links with the most clicks since the given day.
+func (r *LinksRepository) ListHotLinks(ctx context.Context, since time.Time, limit int) ([]*shortener.Link, error) {
+	rows, err := r.pool.Query(ctx, listHotLinksQuery, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*shortener.Link, 0, limit)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// scanLink reads a single links row, mapping a missing row to shortener.ErrNotFound.
+func scanLink(row pgx.Row) (*shortener.Link, error) {
+	var link shortener.Link
 	if err := row.Scan(
 		&link.ID,
 		&link.OwnerID,
+		&link.WorkspaceID,
 		&link.Domain,
 		&link.ShortCode,
 		&link.OriginalURL,
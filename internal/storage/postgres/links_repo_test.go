@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeBatchResults answers each queued insert with the next entry of rows; a nil error yields a new link.
+type fakeBatchResults struct {
+	rows []error
+	next int
+}
+
+func (f *fakeBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, nil }
+func (f *fakeBatchResults) Query() (pgx.Rows, error)         { return nil, errors.New("not implemented") }
+func (f *fakeBatchResults) Close() error                     { return nil }
+
+func (f *fakeBatchResults) QueryRow() pgx.Row {
+	err := f.rows[f.next]
+	f.next++
+	return fakeRow{id: int64(f.next), err: err}
+}
+
+type fakeRow struct {
+	id  int64
+	err error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.id
+	*dest[1].(*time.Time) = time.Now()
+	return nil
+}
+
+type fakeBatchSender struct {
+	results *fakeBatchResults
+}
+
+func (s fakeBatchSender) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return s.results
+}
+
+func TestCreateLinksBatch(t *testing.T) {
+	fkErr := &pgconn.PgError{Code: "23503", Message: "violates foreign key constraint"}
+	abortedErr := &pgconn.PgError{Code: "25P02", Message: "current transaction is aborted"}
+
+	tests := []struct {
+		name    string
+		rows    []error
+		wantErr []error
+		created bool
+	}{
+		{
+			name:    "all inserted",
+			rows:    []error{nil, nil, nil},
+			wantErr: []error{nil, nil, nil},
+			created: true,
+		},
+		{
+			name:    "conflict fails only its item",
+			rows:    []error{nil, pgx.ErrNoRows, nil},
+			wantErr: []error{nil, shortener.ErrAlreadyExists, nil},
+			created: true,
+		},
+		{
+			name:    "non-conflict error in the middle rolls back the batch",
+			rows:    []error{nil, pgx.ErrNoRows, fkErr, abortedErr},
+			wantErr: []error{fkErr, fkErr, fkErr, abortedErr},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := make([]*shortener.Link, len(tt.rows))
+			for i := range links {
+				links[i] = &shortener.Link{Domain: "https://sho.rt", ShortCode: "code"}
+			}
+
+			errs := createLinksBatch(context.Background(), fakeBatchSender{&fakeBatchResults{rows: tt.rows}}, links)
+
+			for i, err := range errs {
+				want := tt.wantErr[i]
+				if want == nil && err != nil || want != nil && !errors.Is(err, want) {
+					t.Errorf("item %d: error = %v, want %v", i, err, want)
+				}
+				if want == nil && links[i].ID == 0 {
+					t.Errorf("item %d: created link has no id", i)
+				}
+				if !tt.created && links[i].ID != 0 {
+					t.Errorf("item %d: rolled back link keeps id %d", i, links[i].ID)
+				}
+			}
+		})
+	}
+}
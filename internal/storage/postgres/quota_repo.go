@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/quota"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuotaRepository is the Postgres implementation of the quota.Repository interface.
+type QuotaRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQuotaRepository creates a new instance of QuotaRepository.
+func NewQuotaRepository(pool *pgxpool.Pool) *QuotaRepository {
+	return &QuotaRepository{pool: pool}
+}
+
+var _ quota.Repository = (*QuotaRepository)(nil)
+
+const getOwnerPlanQuery = `
+SELECT plan
+FROM owner_plans
+WHERE owner_id = $1
+`
+
+// addLinksQuery increments the counter only while it stays within the limit; $4 = 0 means unlimited.
+// No row is returned when the limit would be exceeded.
+const addLinksQuery = `
+INSERT INTO usage_counters (owner_id, period, links_created)
+SELECT $1::bigint, $2::date, $3::bigint
+WHERE $4::bigint = 0 OR $3::bigint <= $4::bigint
+ON CONFLICT (owner_id, period) DO UPDATE
+SET links_created = usage_counters.links_created + EXCLUDED.links_created
+WHERE $4::bigint = 0 OR usage_counters.links_created + EXCLUDED.links_created <= $4::bigint
+RETURNING links_created
+`
+
+const subtractLinksQuery = `
+UPDATE usage_counters
+SET links_created = GREATEST(links_created - $3, 0)
+WHERE owner_id = $1 AND period = $2
+`
+
+const getLinksCreatedQuery = `
+SELECT links_created
+FROM usage_counters
+WHERE owner_id = $1 AND period = $2
+`
+
+// GetOwnerPlan returns the plan assigned to the owner, or "" when none is assigned.
+func (r *QuotaRepository) GetOwnerPlan(ctx context.Context, ownerID int64) (string, error) {
+	var plan string
+	err := r.pool.QueryRow(ctx, getOwnerPlanQuery, ownerID).Scan(&plan)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return plan, err
+}
+
+// AddLinks atomically counts n links against the owner's period counter within limit.
+func (r *QuotaRepository) AddLinks(ctx context.Context, ownerID int64, period time.Time, n, limit int64) (int64, bool, error) {
+	var total int64
+	err := r.pool.QueryRow(ctx, addLinksQuery, ownerID, period, n, limit).Scan(&total)
+	if err == nil {
+		return total, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, err
+	}
+
+	used, err := r.GetLinksCreated(ctx, ownerID, period)
+	if err != nil {
+		return 0, false, err
+	}
+	return used, false, nil
+}
+
+// SubtractLinks refunds n links from the owner's period counter.
+func (r *QuotaRepository) SubtractLinks(ctx context.Context, ownerID int64, period time.Time, n int64) error {
+	_, err := r.pool.Exec(ctx, subtractLinksQuery, ownerID, period, n)
+	return err
+}
+
+// GetLinksCreated returns the owner's link counter for the period, zero when there is none.
+func (r *QuotaRepository) GetLinksCreated(ctx context.Context, ownerID int64, period time.Time) (int64, error) {
+	var created int64
+	err := r.pool.QueryRow(ctx, getLinksCreatedQuery, ownerID, period).Scan(&created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return created, err
+}
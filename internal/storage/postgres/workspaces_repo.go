@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/workspace"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WorkspacesRepository is the Postgres implementation of the workspace.Repository interface.
+type WorkspacesRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWorkspacesRepository creates a new instance of WorkspacesRepository.
+func NewWorkspacesRepository(pool *pgxpool.Pool) *WorkspacesRepository {
+	return &WorkspacesRepository{pool: pool}
+}
+
+var _ workspace.Repository = (*WorkspacesRepository)(nil)
+
+const insertWorkspaceQuery = `
+INSERT INTO workspaces (name)
+VALUES ($1)
+RETURNING id, created_at
+`
+
+const insertWorkspaceAdminQuery = `
+INSERT INTO workspace_members (workspace_id, user_id, role)
+VALUES ($1, $2, 'admin')
+`
+
+const listUserWorkspacesQuery = `
+SELECT w.id, w.name, w.created_at, m.role
+FROM workspaces w
+JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.user_id = $1
+ORDER BY w.id
+`
+
+const getRoleQuery = `
+SELECT role
+FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2
+`
+
+const upsertMemberQuery = `
+INSERT INTO workspace_members (workspace_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (workspace_id, user_id)
+DO UPDATE SET role = EXCLUDED.role
+RETURNING created_at
+`
+
+const removeMemberQuery = `
+DELETE FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2
+`
+
+const listMembersQuery = `
+SELECT workspace_id, user_id, role, created_at
+FROM workspace_members
+WHERE workspace_id = $1
+ORDER BY created_at, user_id
+`
+
+// lockAdminsQuery locks the admin memberships of a workspace so that concurrent demotions
+// and removals are serialized and each sees the admins left by the others.
+const lockAdminsQuery = `
+SELECT user_id
+FROM workspace_members
+WHERE workspace_id = $1 AND role = 'admin'
+FOR UPDATE
+`
+
+// CreateWorkspace inserts a workspace and its first admin in one transaction.
+func (r *WorkspacesRepository) CreateWorkspace(ctx context.Context, ws *workspace.Workspace, creatorID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := tx.QueryRow(ctx, insertWorkspaceQuery, ws.Name).Scan(&ws.ID, &ws.CreatedAt); err != nil {
+		return fmt.Errorf("insert workspace: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertWorkspaceAdminQuery, ws.ID, creatorID); err != nil {
+		return fmt.Errorf("insert admin: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListUserWorkspaces returns the workspaces a user belongs to together with their role.
+func (r *WorkspacesRepository) ListUserWorkspaces(ctx context.Context, userID int64) ([]workspace.Workspace, error) {
+	rows, err := r.pool.Query(ctx, listUserWorkspacesQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]workspace.Workspace, 0)
+	for rows.Next() {
+		var ws workspace.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.CreatedAt, &ws.Role); err != nil {
+			return nil, err
+		}
+		list = append(list, ws)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetRole returns the user's role in a workspace.
+func (r *WorkspacesRepository) GetRole(ctx context.Context, workspaceID, userID int64) (workspace.Role, error) {
+	var role workspace.Role
+	if err := r.pool.QueryRow(ctx, getRoleQuery, workspaceID, userID).Scan(&role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", workspace.ErrNotMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// UpsertMember adds a member or changes their role. It returns workspace.ErrLastAdmin
+// instead of demoting the only admin.
+func (r *WorkspacesRepository) UpsertMember(ctx context.Context, m *workspace.Member) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if m.Role != workspace.RoleAdmin {
+		if err := ensureAnotherAdmin(ctx, tx, m.WorkspaceID, m.UserID); err != nil {
+			return err
+		}
+	}
+	if err := tx.QueryRow(ctx, upsertMemberQuery, m.WorkspaceID, m.UserID, m.Role).Scan(&m.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveMember deletes a membership. It returns workspace.ErrLastAdmin instead of removing the only admin.
+func (r *WorkspacesRepository) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := ensureAnotherAdmin(ctx, tx, workspaceID, userID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, removeMemberQuery, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return workspace.ErrNotMember
+	}
+	return tx.Commit(ctx)
+}
+
+// ensureAnotherAdmin locks the workspace admins and fails with workspace.ErrLastAdmin when
+// userID is the only one. The locks are held until tx ends.
+func ensureAnotherAdmin(ctx context.Context, tx pgx.Tx, workspaceID, userID int64) error {
+	rows, err := tx.Query(ctx, lockAdminsQuery, workspaceID)
+	if err != nil {
+		return fmt.Errorf("lock admins: %w", err)
+	}
+	admins, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("lock admins: %w", err)
+	}
+
+	for _, id := range admins {
+		if id == userID {
+			if len(admins) <= 1 {
+				return workspace.ErrLastAdmin
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// ListMembers returns all members of a workspace.
+func (r *WorkspacesRepository) ListMembers(ctx context.Context, workspaceID int64) ([]workspace.Member, error) {
+	rows, err := r.pool.Query(ctx, listMembersQuery, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]workspace.Member, 0)
+	for rows.Next() {
+		var m workspace.Member
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
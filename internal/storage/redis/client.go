@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Config describes how to reach Redis. ClusterAddrs selects Redis Cluster, MasterName
+// selects Sentinel with SentinelAddrs; otherwise a single node at Addr is used.
+type Config struct {
+	Addr     string
+	DB       int
+	Password string
+
+	// MasterName is the Sentinel master name.
+	MasterName       string
+	SentinelAddrs    []string
+	SentinelPassword string
+
+	// ClusterAddrs are the Cluster seed nodes.
+	ClusterAddrs []string
+
+	TLSEnabled bool
+	// TLSCAFile is a PEM bundle used instead of the system roots.
+	TLSCAFile     string
+	TLSServerName string
+}
+
+// NewClient creates a Redis client for a single node, Sentinel or Cluster deployment.
+func NewClient(cfg Config) (goredis.UniversalClient, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(cfg.ClusterAddrs) > 0:
+		return goredis.NewClusterClient(&goredis.ClusterOptions{
+			Addrs:     cfg.ClusterAddrs,
+			Password:  cfg.Password,
+			TLSConfig: tlsConfig,
+		}), nil
+	case cfg.MasterName != "":
+		if len(cfg.SentinelAddrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel addresses are required with a master name")
+		}
+		return goredis.NewFailoverClient(&goredis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.SentinelAddrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			TLSConfig:        tlsConfig,
+		}), nil
+	default:
+		return goredis.NewClient(&goredis.Options{
+			Addr:      cfg.Addr,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			TLSConfig: tlsConfig,
+		}), nil
+	}
+}
+
+// newTLSConfig builds the client TLS configuration, or nil when TLS is disabled.
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.TLSServerName,
+	}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("redis ca file %s contains no certificates", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
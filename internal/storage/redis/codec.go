@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+)
+
+// Cached links are stored in a compact binary form that keeps only the fields needed to
+// redirect. Domain and short code are part of the key and are not stored.
+//
+// Version 1 layout:
+//
+//	byte    version (codecV1)
+//	byte    flags (flagActive, flagExpires)
+//	uvarint link ID
+//	varint  expiration as Unix nanoseconds, present with flagExpires
+//	bytes   original URL, up to the end of the value
+//
+// Entries written as JSON by older releases start with '{' and are still decoded.
+const codecV1 byte = 1
+
+const (
+	flagActive byte = 1 << iota
+	flagExpires
+)
+
+// errUnknownCodec is returned for cached values in an unsupported format.
+var errUnknownCodec = errors.New("unknown cached link format")
+
+// encodeLink serializes the redirect-relevant fields of link.
+func encodeLink(link *shortener.Link) []byte {
+	buf := make([]byte, 0, 2+2*binary.MaxVarintLen64+len(link.OriginalURL))
+
+	var flags byte
+	if link.IsActive {
+		flags |= flagActive
+	}
+	if link.ExpiresAt != nil {
+		flags |= flagExpires
+	}
+
+	buf = append(buf, codecV1, flags)
+	buf = binary.AppendUvarint(buf, uint64(link.ID))
+	if link.ExpiresAt != nil {
+		buf = binary.AppendVarint(buf, link.ExpiresAt.UnixNano())
+	}
+	return append(buf, link.OriginalURL...)
+}
+
+// decodeLink parses a cached value for the link stored under (domain, code).
+func decodeLink(data []byte, domain, code string) (*shortener.Link, error) {
+	if len(data) == 0 {
+		return nil, errUnknownCodec
+	}
+
+	switch data[0] {
+	case '{':
+		var link shortener.Link
+		if err := json.Unmarshal(data, &link); err != nil {
+			return nil, err
+		}
+		return &link, nil
+	case codecV1:
+		return decodeLinkV1(data[1:], domain, code)
+	}
+	return nil, fmt.Errorf("%w: version %d", errUnknownCodec, data[0])
+}
+
+// decodeLinkV1 parses the version 1 layout that follows the version byte.
+func decodeLinkV1(data []byte, domain, code string) (*shortener.Link, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: truncated value", errUnknownCodec)
+	}
+	flags := data[0]
+	data = data[1:]
+
+	id, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: bad link id", errUnknownCodec)
+	}
+	data = data[n:]
+
+	link := &shortener.Link{
+		ID:        int64(id),
+		Domain:    domain,
+		ShortCode: code,
+		IsActive:  flags&flagActive != 0,
+	}
+
+	if flags&flagExpires != 0 {
+		nanos, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: bad expiration", errUnknownCodec)
+		}
+		data = data[n:]
+		expiresAt := time.Unix(0, nanos).UTC()
+		link.ExpiresAt = &expiresAt
+	}
+
+	link.OriginalURL = string(data)
+	return link, nil
+}
@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/shortener"
+)
+
+func TestLinkCodecRoundTrip(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name string
+		link shortener.Link
+	}{
+		{
+			name: "active without expiration",
+			link: shortener.Link{ID: 1, OriginalURL: "https://example.com/", IsActive: true},
+		},
+		{
+			name: "active with expiration",
+			link: shortener.Link{ID: 123456789, OriginalURL: "https://example.com/a?b=c", IsActive: true, ExpiresAt: &expiresAt},
+		},
+		{
+			name: "inactive without expiration",
+			link: shortener.Link{ID: 42, OriginalURL: "https://example.com/off"},
+		},
+		{
+			name: "inactive with expiration",
+			link: shortener.Link{ID: 7, OriginalURL: "https://example.com/x", ExpiresAt: &expiresAt},
+		},
+		{
+			name: "empty url",
+			link: shortener.Link{ID: 9, IsActive: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.link
+			want.Domain, want.ShortCode = "https://sho.rt", "abc"
+
+			got, err := decodeLink(encodeLink(&want), want.Domain, want.ShortCode)
+			if err != nil {
+				t.Fatalf("decodeLink() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, &want) {
+				t.Errorf("decodeLink() = %+v, want %+v", got, &want)
+			}
+		})
+	}
+}
+
+func TestDecodeLinkLegacyJSON(t *testing.T) {
+	want := benchmarkLink()
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodeLink(data, want.Domain, want.ShortCode)
+	if err != nil {
+		t.Fatalf("decodeLink() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeLink() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeLinkInvalid(t *testing.T) {
+	full := encodeLink(benchmarkLink())
+
+	tests := []struct {
+		name string
+		data []byte
+		// json marks legacy values, whose errors come from encoding/json.
+		json bool
+	}{
+		{name: "empty", data: nil},
+		{name: "version only", data: []byte{codecV1}},
+		{name: "missing id", data: []byte{codecV1, flagActive}},
+		{name: "truncated id", data: []byte{codecV1, flagActive, 0x80}},
+		{name: "missing expiration", data: []byte{codecV1, flagExpires, 0x01}},
+		{name: "truncated expiration", data: []byte{codecV1, flagExpires, 0x01, 0x80, 0x80}},
+		{name: "unknown version", data: append([]byte{codecV1 + 1}, full[1:]...)},
+		{name: "zero version", data: []byte{0, flagActive, 0x01}},
+		{name: "broken json", data: []byte(`{"ID":`), json: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := decodeLink(tt.data, "https://sho.rt", "abc")
+			if err == nil {
+				t.Fatalf("decodeLink() = %+v, want error", link)
+			}
+			if !tt.json && !errors.Is(err, errUnknownCodec) {
+				t.Errorf("decodeLink() error = %v, want %v", err, errUnknownCodec)
+			}
+		})
+	}
+}
+
+func benchmarkLink() *shortener.Link {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	workspaceID := int64(42)
+	return &shortener.Link{
+		ID:          123456789,
+		OwnerID:     987654,
+		WorkspaceID: &workspaceID,
+		Domain:      "https://sho.rt",
+		ShortCode:   "aZ3kQ9xB",
+		OriginalURL: "https://example.com/some/fairly/long/path?utm_source=newsletter&utm_medium=email&utm_campaign=launch",
+		ExpiresAt:   &expiresAt,
+		IsActive:    true,
+		CreatedAt:   time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC),
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	link := benchmarkLink()
+	b.ReportAllocs()
+	var size int
+	for b.Loop() {
+		data, err := json.Marshal(link)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes/value")
+}
+
+func BenchmarkEncodeBinary(b *testing.B) {
+	link := benchmarkLink()
+	b.ReportAllocs()
+	var size int
+	for b.Loop() {
+		size = len(encodeLink(link))
+	}
+	b.ReportMetric(float64(size), "bytes/value")
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	link := benchmarkLink()
+	data, err := json.Marshal(link)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := decodeLink(data, link.Domain, link.ShortCode); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBinary(b *testing.B) {
+	link := benchmarkLink()
+	data := encodeLink(link)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := decodeLink(data, link.Domain, link.ShortCode); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
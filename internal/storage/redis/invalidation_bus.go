@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// invalidationPingInterval is how long the subscriber waits for a message before pinging
+	// the connection to detect a silently dropped subscription.
+	invalidationPingInterval = 30 * time.Second
+	// invalidationRetryDelay is the pause between reconnect attempts.
+	invalidationRetryDelay = time.Second
+)
+
+// LocalCache is an in-process cache tier that the invalidation bus evicts from.
+type LocalCache interface {
+	Evict(domain, code string)
+	Flush()
+}
+
+// invalidationMessage identifies a link whose cached copies must be dropped.
+type invalidationMessage struct {
+	Domain string `json:"domain"`
+	Code   string `json:"code"`
+}
+
+// InvalidationBus broadcasts link invalidations to every API instance over Redis pub/sub.
+type InvalidationBus struct {
+	client  goredis.UniversalClient
+	channel string
+	logger  logger.Logger
+}
+
+// NewInvalidationBus creates a new instance of InvalidationBus publishing on channel.
+func NewInvalidationBus(client goredis.UniversalClient, channel string, logger logger.Logger) *InvalidationBus {
+	return &InvalidationBus{
+		client:  client,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish announces that the cached copies of (domain, code) are stale.
+func (b *InvalidationBus) Publish(ctx context.Context, domain, code string) error {
+	data, err := json.Marshal(invalidationMessage{Domain: domain, Code: code})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Run subscribes to invalidations and evicts them from local until ctx is done.
+// Invalidations published while the subscription is down are lost, so local is flushed
+// when the subscription drops and again once it is re-established.
+func (b *InvalidationBus) Run(ctx context.Context, local LocalCache) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer func() {
+		if err := pubsub.Close(); err != nil {
+			b.logger.Warn("failed to close invalidation subscription", logger.Error(err))
+		}
+	}()
+
+	subscribed := false
+	dropped := false
+
+	for {
+		msg, err := pubsub.ReceiveTimeout(ctx, invalidationPingInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if err = pubsub.Ping(ctx); err == nil {
+					continue
+				}
+			}
+
+			if subscribed {
+				b.logger.Warn("invalidation subscription lost, flushing local cache", logger.Error(err))
+				local.Flush()
+				subscribed = false
+				dropped = true
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(invalidationRetryDelay):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *goredis.Subscription:
+			if m.Kind != "subscribe" {
+				continue
+			}
+			if dropped {
+				b.logger.Info("invalidation subscription restored, flushing local cache")
+				local.Flush()
+			}
+			subscribed = true
+		case *goredis.Message:
+			var inv invalidationMessage
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil {
+				b.logger.Warn("failed to decode invalidation message", logger.Error(err))
+				continue
+			}
+			local.Evict(inv.Domain, inv.Code)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -11,70 +10,130 @@ import (
 	goredis "github.com/redis/go-redis/v9"
 )
 
-// NewClient creates a new Redis client.
-func NewClient(addr string, db int, password string) *goredis.Client {
-	return goredis.NewClient(&goredis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-}
-
 // LinkCache is the Redis implementation of the shortener.LinkCache interface.
 type LinkCache struct {
-	client *goredis.Client
+	client goredis.UniversalClient
+	// staleTTL is how long the stale copy of a link outlives the fresh one; zero disables stale copies.
+	staleTTL time.Duration
 }
 
-// NewLinkCache creates a new instance of LinkCache.
-func NewLinkCache(client *goredis.Client) *LinkCache {
+// NewLinkCache creates a new instance of LinkCache. Every cached link also gets a stale copy
+// kept for staleTTL, served when the database is unavailable.
+func NewLinkCache(client goredis.UniversalClient, staleTTL time.Duration) *LinkCache {
 	return &LinkCache{
-		client: client,
+		client:   client,
+		staleTTL: staleTTL,
 	}
 }
 
-// linkKey generates the Redis key for storing a link.
+// linkKey generates the Redis key for storing a link. All keys of one link share the
+// {domain:code} hash tag, so they land in the same Cluster slot and can be used together
+// in MGET, DEL and MULTI.
 func linkKey(domain, code string) string {
-	return fmt.Sprintf("link:%s:%s", domain, code)
+	return fmt.Sprintf("link:{%s:%s}", domain, code)
+}
+
+// linkStaleKey generates the Redis key for storing the stale copy of a link.
+func linkStaleKey(domain, code string) string {
+	return fmt.Sprintf("link:stale:{%s:%s}", domain, code)
 }
 
 // linkNotFoundKey generates the Redis key for storing a not-found link.
 func linkNotFoundKey(domain, code string) string {
-	return fmt.Sprintf("link:nf:%s:%s", domain, code)
+	return fmt.Sprintf("link:nf:{%s:%s}", domain, code)
+}
+
+// legacyLinkKeys lists the keys of a link in the format used before hash tags were added.
+// Instances of the previous release still read them during a rolling deploy.
+// TODO: remove once no deployment runs the old key format.
+func legacyLinkKeys(domain, code string) []string {
+	return []string{
+		fmt.Sprintf("link:%s:%s", domain, code),
+		fmt.Sprintf("link:stale:%s:%s", domain, code),
+		fmt.Sprintf("link:nf:%s:%s", domain, code),
+	}
 }
 
 // GetByCode retrieves a link from the cache by its domain and short code.
+// It returns shortener.ErrNotFound when the code is marked as not found and (nil, nil) on a miss.
 func (c *LinkCache) GetByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
-	key := linkKey(domain, code)
-
-	data, err := c.client.Get(ctx, key).Bytes()
+	vals, err := c.client.MGet(ctx, linkKey(domain, code), linkNotFoundKey(domain, code)).Result()
 	if err != nil {
-		if errors.Is(err, goredis.Nil) {
-			return nil, nil
-		}
 		return nil, err
 	}
 
-	var link shortener.Link
-	if err := json.Unmarshal(data, &link); err != nil {
+	data, ok := vals[0].(string)
+	if !ok {
+		if vals[1] != nil {
+			return nil, shortener.ErrNotFound
+		}
+		return nil, nil
+	}
+	return decodeLink([]byte(data), domain, code)
+}
+
+// GetStaleByCode retrieves the stale copy of a link, or (nil, nil) when there is none.
+func (c *LinkCache) GetStaleByCode(ctx context.Context, domain, code string) (*shortener.Link, error) {
+	data, err := c.client.Get(ctx, linkStaleKey(domain, code)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
-	return &link, nil
+	return decodeLink(data, domain, code)
 }
 
-// SetByCode stores a link in the cache with a specified TTL.
+// SetByCode stores a link in the cache with a specified TTL and clears its not-found marker.
 func (c *LinkCache) SetByCode(ctx context.Context, link *shortener.Link, ttl time.Duration) error {
-	key := linkKey(link.Domain, link.ShortCode)
+	pipe := c.client.TxPipeline()
+	c.queueSet(ctx, pipe, link, encodeLink(link), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
 
-	data, err := json.Marshal(link)
-	if err != nil {
-		return err
+// SetManyByCode stores several links in one pipelined round trip and clears their not-found markers.
+func (c *LinkCache) SetManyByCode(ctx context.Context, entries []shortener.CacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	pipe := c.client.Pipeline()
+	for _, e := range entries {
+		c.queueSet(ctx, pipe, e.Link, encodeLink(e.Link), e.TTL)
 	}
 
-	return c.client.Set(ctx, key, data, ttl).Err()
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-// SetNotFound marks a link as not found in the cache with a specified TTL.
+// queueSet adds the commands caching a link, its stale copy and clearing its not-found marker.
+func (c *LinkCache) queueSet(ctx context.Context, pipe goredis.Pipeliner, link *shortener.Link, data []byte, ttl time.Duration) {
+	pipe.Set(ctx, linkKey(link.Domain, link.ShortCode), data, ttl)
+	if c.staleTTL > 0 {
+		pipe.Set(ctx, linkStaleKey(link.Domain, link.ShortCode), data, max(c.staleTTL, ttl))
+	}
+	pipe.Del(ctx, linkNotFoundKey(link.Domain, link.ShortCode))
+}
+
+// SetNotFound marks a link as not found in the cache with a specified TTL and drops its stale copy.
 func (c *LinkCache) SetNotFound(ctx context.Context, domain, code string, ttl time.Duration) error {
-	key := linkNotFoundKey(domain, code)
-	return c.client.Set(ctx, key, "1", ttl).Err()
+	pipe := c.client.TxPipeline()
+	pipe.Set(ctx, linkNotFoundKey(domain, code), "1", ttl)
+	pipe.Del(ctx, linkStaleKey(domain, code))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Delete removes a cached link, its stale copy and its not-found marker so the next lookup
+// reloads it from storage. Keys in the legacy format are removed too; they may live in other
+// Cluster slots, so each one gets its own DEL.
+func (c *LinkCache) Delete(ctx context.Context, domain, code string) error {
+	pipe := c.client.Pipeline()
+	pipe.Del(ctx, linkKey(domain, code), linkStaleKey(domain, code), linkNotFoundKey(domain, code))
+	for _, key := range legacyLinkKeys(domain, code) {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
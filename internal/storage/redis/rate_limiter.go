@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/ratelimit"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RateLimiter is the Redis implementation of the ratelimit.Limiter interface.
+// Buckets are shared by all API instances.
+type RateLimiter struct {
+	client goredis.UniversalClient
+}
+
+// NewRateLimiter creates a new instance of RateLimiter.
+func NewRateLimiter(client goredis.UniversalClient) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+var _ ratelimit.Limiter = (*RateLimiter)(nil)
+
+// gcraScript runs GCRA atomically using the Redis clock. All times are in microseconds.
+// KEYS[1] - bucket key; ARGV[1] - emission interval; ARGV[2] - burst tolerance (the window).
+// Returns {allowed, remaining, reset_after, retry_after}.
+var gcraScript = goredis.NewScript(`
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+
+if now < allow_at then
+  return {0, 0, tat - now, allow_at - now}
+end
+
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), new_tat - now, 0}
+`)
+
+// rateLimitKey generates the Redis key for a rate limit bucket.
+func rateLimitKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s", key)
+}
+
+// Allow consumes one request from the bucket identified by key.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	if !limit.Enabled() {
+		return ratelimit.Result{Allowed: true}, nil
+	}
+
+	interval := limit.Window.Microseconds() / int64(limit.Requests)
+	vals, err := gcraScript.Run(ctx, l.client, []string{rateLimitKey(key)}, interval, limit.Window.Microseconds()).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if len(vals) != 4 {
+		return ratelimit.Result{}, fmt.Errorf("unexpected rate limit script result: %v", vals)
+	}
+
+	return ratelimit.Result{
+		Allowed:    vals[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(vals[1]),
+		ResetAfter: time.Duration(vals[2]) * time.Microsecond,
+		RetryAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
+}
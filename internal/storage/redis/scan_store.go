@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/scan"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ScanStore is the Redis implementation of the scan.Store interface. Its keys live in the
+// same "link:nf:" key space as the not-found link markers.
+type ScanStore struct {
+	client goredis.UniversalClient
+}
+
+// NewScanStore creates a new instance of ScanStore.
+func NewScanStore(client goredis.UniversalClient) *ScanStore {
+	return &ScanStore{client: client}
+}
+
+var _ scan.Store = (*ScanStore)(nil)
+
+// recordMissScript counts a miss in a fixed window and sets the block once the threshold is reached.
+// KEYS[1] - miss counter; KEYS[2] - block marker; ARGV[1] - window ms; ARGV[2] - threshold;
+// ARGV[3] - block ms. Returns 1 if this call created the block.
+var recordMissScript = goredis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+if n >= tonumber(ARGV[2]) then
+  if redis.call("SET", KEYS[2], "1", "PX", ARGV[3], "NX") then
+    return 1
+  end
+end
+return 0
+`)
+
+// scanMissKey generates the Redis key counting not-found lookups of a client.
+// It shares the {ip} hash tag with scanBlockKey so that recordMissScript touches a single Cluster slot.
+func scanMissKey(ip string) string {
+	return fmt.Sprintf("link:nf:ip:{%s}", ip)
+}
+
+// scanBlockKey generates the Redis key marking a blocked client.
+func scanBlockKey(ip string) string {
+	return fmt.Sprintf("link:nf:block:{%s}", ip)
+}
+
+// RecordMiss counts a not-found lookup for ip and blocks it once threshold is reached within window.
+func (s *ScanStore) RecordMiss(ctx context.Context, ip string, threshold int, window, blockFor time.Duration) (bool, error) {
+	created, err := recordMissScript.Run(ctx, s.client,
+		[]string{scanMissKey(ip), scanBlockKey(ip)},
+		window.Milliseconds(), threshold, blockFor.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return created == 1, nil
+}
+
+// BlockedFor returns the remaining block time for ip, or zero when it is not blocked.
+func (s *ScanStore) BlockedFor(ctx context.Context, ip string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, scanBlockKey(ip)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL reports negative values for missing keys and keys without expiry.
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
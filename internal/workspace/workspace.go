@@ -0,0 +1,240 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PavelKhromykhGo/url-shortener/internal/logger"
+)
+
+var (
+	// ErrNotFound is returned when a workspace does not exist or the user is not a member of it.
+	ErrNotFound = errors.New("workspace not found")
+	// ErrNotMember is returned by repositories when the user has no membership in the workspace.
+	ErrNotMember = errors.New("not a workspace member")
+	// ErrForbidden is returned when the user's role does not allow the requested action.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidInput is returned for malformed workspace or membership input.
+	ErrInvalidInput = errors.New("invalid workspace input")
+	// ErrLastAdmin is returned by repositories when a change would leave a workspace without an admin.
+	ErrLastAdmin = fmt.Errorf("%w: workspace must keep at least one admin", ErrInvalidInput)
+)
+
+// Role is a member's role within a workspace.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Action is an operation guarded by workspace roles.
+type Action int
+
+const (
+	// ActionView allows reading links and their statistics.
+	ActionView Action = iota
+	// ActionEdit allows creating, changing and deleting links.
+	ActionEdit
+	// ActionAdmin allows managing members and transferring links out of the workspace.
+	ActionAdmin
+)
+
+// rank orders roles by privilege.
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// Allows reports whether the role permits the action.
+func (r Role) Allows(a Action) bool {
+	switch a {
+	case ActionView:
+		return r.rank() >= RoleViewer.rank()
+	case ActionEdit:
+		return r.rank() >= RoleEditor.rank()
+	case ActionAdmin:
+		return r.rank() >= RoleAdmin.rank()
+	}
+	return false
+}
+
+// Workspace groups links shared by a team.
+type Workspace struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+	// Role is the requesting user's role, filled in by listings.
+	Role Role
+}
+
+// Member is a user's membership in a workspace.
+type Member struct {
+	WorkspaceID int64
+	UserID      int64
+	Role        Role
+	CreatedAt   time.Time
+}
+
+// Repository persists workspaces and memberships.
+type Repository interface {
+	// CreateWorkspace inserts the workspace and makes creatorID its admin.
+	CreateWorkspace(ctx context.Context, ws *Workspace, creatorID int64) error
+	ListUserWorkspaces(ctx context.Context, userID int64) ([]Workspace, error)
+	// GetRole returns the user's role or ErrNotMember.
+	GetRole(ctx context.Context, workspaceID, userID int64) (Role, error)
+	// UpsertMember adds a member or changes their role. Demoting the last admin fails with
+	// ErrLastAdmin; the check and the write are atomic.
+	UpsertMember(ctx context.Context, m *Member) error
+	// RemoveMember deletes a membership or returns ErrNotMember. Removing the last admin fails
+	// with ErrLastAdmin; the check and the delete are atomic.
+	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	ListMembers(ctx context.Context, workspaceID int64) ([]Member, error)
+}
+
+// Service manages workspaces and authorizes actions within them.
+type Service interface {
+	CreateWorkspace(ctx context.Context, userID int64, name string) (*Workspace, error)
+	ListWorkspaces(ctx context.Context, userID int64) ([]Workspace, error)
+	ListMembers(ctx context.Context, userID, workspaceID int64) ([]Member, error)
+	SetMember(ctx context.Context, userID, workspaceID, memberID int64, role Role) (*Member, error)
+	RemoveMember(ctx context.Context, userID, workspaceID, memberID int64) error
+	// Authorize returns nil if userID may perform action in workspaceID, ErrNotFound if the
+	// user is not a member and ErrForbidden if the role is insufficient.
+	Authorize(ctx context.Context, userID, workspaceID int64, action Action) error
+}
+
+type service struct {
+	repo   Repository
+	logger logger.Logger
+}
+
+// NewService constructs a workspace Service backed by the provided repository.
+func NewService(repo Repository, logger logger.Logger) Service {
+	return &service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateWorkspace creates a workspace with userID as its first admin.
+func (s *service) CreateWorkspace(ctx context.Context, userID int64, name string) (*Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1..100 characters", ErrInvalidInput)
+	}
+
+	ws := &Workspace{Name: name, Role: RoleAdmin}
+	if err := s.repo.CreateWorkspace(ctx, ws, userID); err != nil {
+		return nil, fmt.Errorf("create workspace: %w", err)
+	}
+
+	s.logger.Info("workspace created",
+		logger.Int64("workspace_id", ws.ID),
+		logger.Int64("user_id", userID),
+	)
+	return ws, nil
+}
+
+// ListWorkspaces returns the workspaces userID belongs to.
+func (s *service) ListWorkspaces(ctx context.Context, userID int64) ([]Workspace, error) {
+	list, err := s.repo.ListUserWorkspaces(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list workspaces: %w", err)
+	}
+	return list, nil
+}
+
+// ListMembers returns the members of a workspace visible to userID.
+func (s *service) ListMembers(ctx context.Context, userID, workspaceID int64) ([]Member, error) {
+	if err := s.Authorize(ctx, userID, workspaceID, ActionView); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	return members, nil
+}
+
+// SetMember adds memberID to the workspace or changes their role. Only admins may do this.
+func (s *service) SetMember(ctx context.Context, userID, workspaceID, memberID int64, role Role) (*Member, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidInput, role)
+	}
+	if memberID <= 0 {
+		return nil, fmt.Errorf("%w: invalid user ID", ErrInvalidInput)
+	}
+	if err := s.Authorize(ctx, userID, workspaceID, ActionAdmin); err != nil {
+		return nil, err
+	}
+
+	m := &Member{WorkspaceID: workspaceID, UserID: memberID, Role: role}
+	if err := s.repo.UpsertMember(ctx, m); err != nil {
+		if errors.Is(err, ErrLastAdmin) {
+			return nil, ErrLastAdmin
+		}
+		return nil, fmt.Errorf("upsert member: %w", err)
+	}
+
+	s.logger.Info("workspace member set",
+		logger.Int64("workspace_id", workspaceID),
+		logger.Int64("member_id", memberID),
+		logger.String("role", string(role)),
+	)
+	return m, nil
+}
+
+// RemoveMember removes memberID from the workspace. Admins may remove anyone; members may leave.
+func (s *service) RemoveMember(ctx context.Context, userID, workspaceID, memberID int64) error {
+	action := ActionAdmin
+	if memberID == userID {
+		action = ActionView
+	}
+	if err := s.Authorize(ctx, userID, workspaceID, action); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveMember(ctx, workspaceID, memberID); err != nil {
+		if errors.Is(err, ErrNotMember) {
+			return ErrNotFound
+		}
+		if errors.Is(err, ErrLastAdmin) {
+			return ErrLastAdmin
+		}
+		return fmt.Errorf("remove member: %w", err)
+	}
+	return nil
+}
+
+// Authorize checks that userID may perform action in workspaceID.
+func (s *service) Authorize(ctx context.Context, userID, workspaceID int64, action Action) error {
+	role, err := s.repo.GetRole(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotMember) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get role: %w", err)
+	}
+	if !role.Allows(action) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -11,6 +11,10 @@ type APIMetrics struct {
 
 	LinksCreatedTotal *prometheus.CounterVec
 	RedirectsTotal    *prometheus.CounterVec
+	RateLimitedTotal  *prometheus.CounterVec
+
+	ScanDetectionsTotal prometheus.Counter
+	ScanThrottledTotal  *prometheus.CounterVec
 
 	KafkaProduceTotal    *prometheus.CounterVec
 	KafkaProduceDuration *prometheus.HistogramVec
@@ -57,6 +61,29 @@ func NewAPIMetrics(req prometheus.Registerer) *APIMetrics {
 			[]string{"result"},
 		),
 
+		RateLimitedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "api_rate_limited_total",
+				Help: "Total number of requests rejected by rate limiting",
+			},
+			[]string{"group"},
+		),
+
+		ScanDetectionsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "api_scan_detections_total",
+				Help: "Total number of clients flagged for short code scanning",
+			},
+		),
+
+		ScanThrottledTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "api_scan_throttled_requests_total",
+				Help: "Total number of redirect requests blocked or tarpitted by scan detection",
+			},
+			[]string{"mode"},
+		),
+
 		KafkaProduceTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "api_kafka_produce_total",
@@ -80,6 +107,9 @@ func NewAPIMetrics(req prometheus.Registerer) *APIMetrics {
 		m.RequestDuration,
 		m.LinksCreatedTotal,
 		m.RedirectsTotal,
+		m.RateLimitedTotal,
+		m.ScanDetectionsTotal,
+		m.ScanThrottledTotal,
 		m.KafkaProduceTotal,
 		m.KafkaProduceDuration,
 	)
@@ -19,6 +19,15 @@ var (
 	KafkaConsumerProcessedTotal  *prometheus.CounterVec
 	KafkaConsumerProcessDuration *prometheus.HistogramVec
 	KafkaConsumerLagSeconds      *prometheus.HistogramVec
+
+	ShortCodeCollisionsTotal       prometheus.Counter
+	ShortCodeRetriesExhaustedTotal prometheus.Counter
+	ShortCodePoolDepth             prometheus.Gauge
+	ShortCodePoolEmptyTotal        prometheus.Counter
+
+	LinkCacheLookupsTotal *prometheus.CounterVec
+	StaleLinksServedTotal prometheus.Counter
+	LinkCacheBreakerState prometheus.Gauge
 )
 
 // MustInit initializes and registers the Prometheus metrics.
@@ -115,6 +124,77 @@ func MustInit(serviceName string) {
 			[]string{"topic"},
 		)
 
+		ShortCodeCollisionsTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shortener_code_collisions_total",
+				Help: "Total number of generated short codes that collided with existing ones",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
+		ShortCodeRetriesExhaustedTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shortener_code_retries_exhausted_total",
+				Help: "Total number of link creations that failed after exhausting short code retries",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
+		LinkCacheLookupsTotal = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "link_cache_lookups_total",
+				Help: "Total number of link cache lookups by cache tier and result",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+			[]string{"tier", "result"},
+		)
+
+		StaleLinksServedTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shortener_stale_links_served_total",
+				Help: "Total number of links served from the stale cache because storage was unavailable",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
+		ShortCodePoolDepth = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "shortener_code_pool_depth",
+				Help: "Number of reserved short codes buffered in the code pool",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
+		ShortCodePoolEmptyTotal = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shortener_code_pool_empty_total",
+				Help: "Total number of short code requests that found the code pool empty and waited for a refill",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
+		LinkCacheBreakerState = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "link_cache_breaker_state",
+				Help: "State of the link cache circuit breaker: 0 closed, 1 half-open, 2 open",
+				ConstLabels: prometheus.Labels{
+					"service": serviceName,
+				},
+			},
+		)
+
 		prometheus.MustRegister(
 			HTTPRequestsTotal,
 			HTTPRequestDuration,
@@ -124,6 +204,13 @@ func MustInit(serviceName string) {
 			KafkaConsumerProcessedTotal,
 			KafkaConsumerProcessDuration,
 			KafkaConsumerLagSeconds,
+			ShortCodeCollisionsTotal,
+			ShortCodeRetriesExhaustedTotal,
+			LinkCacheLookupsTotal,
+			StaleLinksServedTotal,
+			LinkCacheBreakerState,
+			ShortCodePoolDepth,
+			ShortCodePoolEmptyTotal,
 		)
 
 	})
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_links_owner_created_at ON links(owner_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_links_owner_url_md5;
+DROP TABLE IF EXISTS idempotency_keys;
\ No newline at end of file
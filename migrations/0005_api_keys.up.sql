@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         BIGSERIAL PRIMARY KEY,
+    owner_id   BIGINT      NOT NULL,
+    name       TEXT        NOT NULL DEFAULT '',
+    key_prefix TEXT        NOT NULL,
+    key_hash   TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_owner_id ON api_keys(owner_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_links_workspace_created_at;
+ALTER TABLE links DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT      NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id      BIGINT      NOT NULL,
+    role         TEXT        NOT NULL CHECK (role IN ('admin', 'editor', 'viewer')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- RESTRICT: links must be deleted or transferred through the service so caches get invalidated.
+ALTER TABLE links ADD COLUMN IF NOT EXISTS workspace_id BIGINT NULL REFERENCES workspaces(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_links_workspace_created_at ON links(workspace_id, created_at DESC, id DESC)
+    WHERE workspace_id IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS owner_plans (
+    owner_id   BIGINT PRIMARY KEY,
+    plan       TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS usage_counters (
+    owner_id      BIGINT NOT NULL,
+    period        DATE   NOT NULL,
+    links_created BIGINT NOT NULL DEFAULT 0 CHECK (links_created >= 0),
+    PRIMARY KEY (owner_id, period)
+);
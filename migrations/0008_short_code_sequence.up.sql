@@ -0,0 +1 @@
+CREATE SEQUENCE IF NOT EXISTS short_code_seq AS BIGINT START WITH 1;
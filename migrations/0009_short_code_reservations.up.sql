@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS short_code_reservations (
+    domain      TEXT        NOT NULL,
+    short_code  VARCHAR(32) NOT NULL,
+    reserved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (domain, short_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_code_reservations_reserved_at ON short_code_reservations(reserved_at);